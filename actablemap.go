@@ -0,0 +1,79 @@
+package action
+
+// ActableMap is a map of K to V whose operations run on a runner.
+type ActableMap[K comparable, V any] struct {
+	r Runners
+	m map[K]V
+}
+
+// NewActableMap returns an empty map whose operations run on r.
+func NewActableMap[K comparable, V any](r Runners) *ActableMap[K, V] {
+	return &ActableMap[K, V]{
+		r: r,
+		m: make(map[K]V),
+	}
+}
+
+// Get returns the value stored for the key, if any.
+func (m *ActableMap[K, V]) Get(key K) (V, bool) {
+	return ActGet2(m.r, func() (V, bool) {
+		v, ok := m.m[key]
+		return v, ok
+	})
+}
+
+// Set sets the value for the key.
+func (m *ActableMap[K, V]) Set(key K, value V) {
+	Act(m.r, func() {
+		m.m[key] = value
+	})
+}
+
+// Delete deletes the value for the key.
+func (m *ActableMap[K, V]) Delete(key K) {
+	Act(m.r, func() {
+		delete(m.m, key)
+	})
+}
+
+// Len returns the number of entries.
+func (m *ActableMap[K, V]) Len() int {
+	return ActGet(m.r, func() int {
+		return len(m.m)
+	})
+}
+
+// GetOrCompute returns the existing value for the key if present. Otherwise,
+// it stores and returns the value returned by compute, which runs on the
+// runner and must not call the map. loaded reports whether the value was
+// already there.
+func (m *ActableMap[K, V]) GetOrCompute(key K, compute func() V) (value V, loaded bool) {
+	return ActGet2(m.r, func() (V, bool) {
+		if v, ok := m.m[key]; ok {
+			return v, true
+		}
+		v := compute()
+		m.m[key] = v
+		return v, false
+	})
+}
+
+// Range calls f for each key and value, stopping if f returns false.
+// It iterates over a snapshot taken in a single action, so f runs outside of
+// the runner and may call the other methods of the map.
+func (m *ActableMap[K, V]) Range(f func(key K, value V) bool) {
+	keys, values := ActGet2(m.r, func() ([]K, []V) {
+		keys := make([]K, 0, len(m.m))
+		values := make([]V, 0, len(m.m))
+		for k, v := range m.m {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		return keys, values
+	})
+	for i := range keys {
+		if !f(keys[i], values[i]) {
+			return
+		}
+	}
+}
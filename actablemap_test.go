@@ -0,0 +1,67 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newActableMap(t *testing.T) *action.ActableMap[string, int] {
+	r := action.New()
+	require.NoError(t, r.Start(t.Context()))
+	return action.NewActableMap[string, int](r)
+}
+
+func TestActableMap(t *testing.T) {
+	t.Run("Should set, get and delete values", func(t *testing.T) {
+		m := newActableMap(t)
+		_, ok := m.Get("a")
+		require.False(t, ok)
+		m.Set("a", 1)
+		v, ok := m.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+		require.Equal(t, 1, m.Len())
+		m.Delete("a")
+		require.Zero(t, m.Len())
+	})
+	t.Run("Should compute missing values only", func(t *testing.T) {
+		m := newActableMap(t)
+		calls := 0
+		compute := func() int {
+			calls++
+			return 42
+		}
+		v, loaded := m.GetOrCompute("a", compute)
+		require.False(t, loaded)
+		require.Equal(t, 42, v)
+		v, loaded = m.GetOrCompute("a", compute)
+		require.True(t, loaded)
+		require.Equal(t, 42, v)
+		require.Equal(t, 1, calls)
+	})
+	t.Run("Should range over a snapshot", func(t *testing.T) {
+		m := newActableMap(t)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		got := map[string]int{}
+		m.Range(func(k string, v int) bool {
+			got[k] = v
+			m.Delete(k)
+			return true
+		})
+		require.Equal(t, map[string]int{"a": 1, "b": 2}, got)
+		require.Zero(t, m.Len())
+	})
+	t.Run("Should stop ranging when f returns false", func(t *testing.T) {
+		m := newActableMap(t)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		n := 0
+		m.Range(func(string, int) bool {
+			n++
+			return false
+		})
+		require.Equal(t, 1, n)
+	})
+}
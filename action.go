@@ -1,126 +1,245 @@
 package action
 
+import (
+	"context"
+	"time"
+)
+
 type Action func()
 type ActionErr func() error
 type ActionReturn[T any] func() T
 type ActionReturnWithError[T any] func() (T, error)
 type ActionReturn2[A, B any] func() (A, B)
 type ActionReturn3[A, B, C any] func() (A, B, C)
+type ActionContext func(ctx context.Context) error
 
 type Actioner chan Action
 
-// ActGetErr returns `T` and  an error of the action
+type inliner interface {
+	acquireInline() bool
+	releaseInline()
+}
+
+// acquireInline reports whether the action can run on the caller's
+// goroutine, see WithInlineExecution. On success, the returned inliner must
+// be released once the action is done.
+func acquireInline(r Runners) (inliner, bool) {
+	ir, ok := r.(inliner)
+	return ir, ok && ir.acquireInline()
+}
+
+// awaitedSender is implemented by runners giving priority to the actions
+// their caller waits for, see WithReplyPriority.
+type awaitedSender interface {
+	sendAwaited(Action) context.Context
+}
+
+// sendAwaited enqueues an action the caller blocks on, and returns the
+// context to wait on: the one of the runner run that queued the action, so
+// a restart cannot leave the caller waiting for a dropped action.
+func sendAwaited(r Runners, action Action) context.Context {
+	if as, ok := r.(awaitedSender); ok {
+		if ctx := as.sendAwaited(action); ctx != nil {
+			return ctx
+		}
+		return r.Ctx()
+	}
+	r.Send(action)
+	return r.Ctx()
+}
+
+// ActGetErr returns `T` and  an error of the action, or an error wrapping
+// ErrStopped if the runner stopped first.
 func ActGetErr[T any](r Runners, action ActionReturnWithError[T]) (T, error) {
-	c := make(chan struct {
-		t   T
-		err error
-	}, 1)
-
-	r.Send(func() {
-		t, err := action()
-		c <- struct {
-			t   T
-			err error
-		}{t, err}
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	c := getReply[Result[T]]()
+	ctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- Err[T](pe) })()
+		}
+		c <- ResultOf(action())
 	})
-	ctx := r.Ctx()
 	select {
 	case <-ctx.Done():
 		var t T
-		return t, ctx.Err()
+		return t, stopped(ctx)
 	case p := <-c:
-		return p.t, p.err
+		putReply(c)
+		return p.Unwrap()
 	}
 }
 
-// ActErr returns the error of the action
+// ActErr returns the error of the action, or an error wrapping ErrStopped
+// if the runner stopped first.
 func ActErr(r Runners, action ActionErr) error {
-	c := make(chan error, 1)
-	r.Send(func() {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	c := getReply[error]()
+	ctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- pe })()
+		}
 		c <- action()
 	})
-	ctx := r.Ctx()
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return stopped(ctx)
 	case p := <-c:
+		putReply(c)
 		return p
 	}
 }
 
 // Act only execute the action
 func Act(r Runners, action Action) {
-	c := make(chan any, 1)
-	r.Send(func() {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		action()
+		return
+	}
+	c := getReply[struct{}]()
+	ctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- struct{}{} })()
+		}
 		action()
 		c <- struct{}{}
 	})
-	ctx := r.Ctx()
 	select {
 	case <-ctx.Done():
 		return
 	case <-c:
+		putReply(c)
 		return
 	}
 }
 
+// Tell enqueues the action without waiting for it to be executed.
+// Unlike Act, it allocates nothing: reuse the same Action value for
+// high-frequency updates to keep the caller allocation-free.
+func Tell(r Runners, action Action) {
+	r.Send(action)
+}
+
+// TellErr is like Tell, but returns an error wrapping ErrStopped instead of
+// panicking if the runner is stopped, see Runner.SendErr.
+func TellErr(r Runners, action Action) (err error) {
+	if s, ok := r.(interface{ SendErr(Action) error }); ok {
+		return s.SendErr(action)
+	}
+	if ctx := r.Ctx(); ctx != nil && ctx.Err() != nil {
+		return stopped(ctx)
+	}
+	defer func() {
+		if recover() != nil {
+			err = stopped(r.Ctx())
+		}
+	}()
+	r.Send(action)
+	return nil
+}
+
+// TryAct is like Act but returns false without running the action if the
+// runner queue is full, for runners supporting it such as *Runner. Other
+// runners always run the action.
+func TryAct(r Runners, action Action) bool {
+	ts, ok := r.(interface{ TrySend(Action) bool })
+	if !ok {
+		Act(r, action)
+		return true
+	}
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		action()
+		return true
+	}
+	c := getReply[struct{}]()
+	if !ts.TrySend(func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- struct{}{} })()
+		}
+		action()
+		c <- struct{}{}
+	}) {
+		putReply(c)
+		return false
+	}
+	ctx := r.Ctx()
+	select {
+	case <-ctx.Done():
+	case <-c:
+		putReply(c)
+	}
+	return true
+}
+
 // ActGet returns `T` of the action
 func ActGet[T any](r Runners, action ActionReturn[T]) T {
-	c := make(chan T)
-	r.Send(func() {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	c := getReply[T]()
+	ctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- *new(T) })()
+		}
 		c <- action()
 	})
-	ctx := r.Ctx()
 	select {
 	case <-ctx.Done():
 		var t T
 		return t
 	case p := <-c:
+		putReply(c)
 		return p
 	}
 }
 
 // ActGet2 returns `A` and `B` of the action
 func ActGet2[A, B any](r Runners, action ActionReturn2[A, B]) (A, B) {
-	c := make(chan struct {
-		a A
-		b B
-	}, 1)
-
-	r.Send(func() {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	c := getReply[Pair[A, B]]()
+	ctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- Pair[A, B]{} })()
+		}
 		a, b := action()
-		c <- struct {
-			a A
-			b B
-		}{a: a, b: b}
+		c <- Pair[A, B]{a, b}
 	})
-	ctx := r.Ctx()
 	select {
 	case <-ctx.Done():
 		var a A
 		var b B
 		return a, b
 	case p := <-c:
-		return p.a, p.b
+		putReply(c)
+		return p.Unpack()
 	}
 }
 
 // ActGet3 returns `A`, `B  and `C` of the action
 func ActGet3[A, B, C any](r Runners, action ActionReturn3[A, B, C]) (A, B, C) {
-	ch := make(chan struct {
-		a A
-		b B
-		c C
-	}, 1)
-	r.Send(func() {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	ch := getReply[Triple[A, B, C]]()
+	ctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { ch <- Triple[A, B, C]{} })()
+		}
 		a, b, c := action()
-		ch <- struct {
-			a A
-			b B
-			c C
-		}{a: a, b: b, c: c}
+		ch <- Triple[A, B, C]{a, b, c}
 	})
-	ctx := r.Ctx()
 	select {
 	case <-ctx.Done():
 		var a A
@@ -128,6 +247,205 @@ func ActGet3[A, B, C any](r Runners, action ActionReturn3[A, B, C]) (A, B, C) {
 		var c C
 		return a, b, c
 	case p := <-ch:
-		return p.a, p.b, p.c
+		putReply(ch)
+		return p.Unpack()
+	}
+}
+
+// ActForEach takes a snapshot of an actor-owned collection in a single
+// action, then calls each for every element outside the runner, so slow work
+// per element does not hold the runner. The snapshot must copy what it
+// returns: each must not see elements the actor keeps mutating.
+func ActForEach[T any](r Runners, snapshot func() []T, each func(T)) {
+	for _, v := range ActGet(r, snapshot) {
+		each(v)
+	}
+}
+
+// ActWithContext returns the error of the action, which receives a context
+// derived from the runner's, expiring after the runner action timeout if
+// one is set (see WithActionTimeout), and carrying the values of ctx for
+// the keys given to WithContextKeys. It stops waiting when ctx is done, in
+// which case the action context is cancelled too, or the action skipped if
+// it did not start yet, and returns the error of ctx, wrapped in ErrTimeout
+// if its deadline passed. It returns an error wrapping ErrStopped if the
+// runner stopped first. With WithQuota, it fails with ErrQuotaExceeded when
+// the caller's tenant is over its rate.
+func ActWithContext(ctx context.Context, r Runners, action ActionContext) error {
+	if err := admit(ctx, r); err != nil {
+		return err
+	}
+	c := getReply[error]()
+	values := carriedValues(ctx, r)
+	enqueued := time.Now()
+	rctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- pe })()
+		}
+		if ctx.Err() != nil {
+			err := abandoned(ctx)
+			reportDropped(r, func() { _ = action(r.Ctx()) }, enqueued, err)
+			c <- err
+			return
+		}
+		c <- runWithContext(ctx, r, values, enqueued, action)
+	})
+	select {
+	case <-ctx.Done():
+		return abandoned(ctx)
+	case <-rctx.Done():
+		return stopped(rctx)
+	case p := <-c:
+		putReply(c)
+		return p
+	}
+}
+
+// admit checks the rate of the caller's tenant against the quota of r, if
+// any, see WithQuota.
+func admit(ctx context.Context, r Runners) error {
+	if q, ok := r.(interface{ admit(context.Context) error }); ok {
+		return q.admit(ctx)
+	}
+	return nil
+}
+
+// carriedValues returns the values of ctx for the keys of WithContextKeys,
+// alternating keys and values.
+func carriedValues(ctx context.Context, r Runners) []any {
+	ck, ok := r.(interface{ contextKeys() []any })
+	if !ok || len(ck.contextKeys()) == 0 {
+		return nil
+	}
+	values := make([]any, 0, 2*len(ck.contextKeys()))
+	for _, k := range ck.contextKeys() {
+		if v := ctx.Value(k); v != nil {
+			values = append(values, k, v)
+		}
+	}
+	return values
+}
+
+func runWithContext(caller context.Context, r Runners, values []any, enqueued time.Time, action ActionContext) (err error) {
+	ctx := r.Ctx()
+	if caller.Done() != nil {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		stop := context.AfterFunc(caller, func() {
+			cancel(context.Cause(caller))
+		})
+		defer stop()
+	}
+	for i := 0; i < len(values); i += 2 {
+		ctx = context.WithValue(ctx, values[i], values[i+1])
+	}
+	if t, ok := r.(interface{ actionTimeout() time.Duration }); ok && t.actionTimeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.actionTimeout())
+		defer cancel()
 	}
+	ctx, end := callerSpan(caller, ctx, r, enqueued)
+	defer func() { end(err) }()
+	return action(ctx)
+}
+
+// ActSliced runs the steps in order on the runner, sending each one only
+// once the previous one is done, so other queued actions interleave with a
+// long operation instead of waiting for all of it. It returns after the last
+// step, or early if the runner stops. Steps must not rely on the state being
+// untouched between them.
+func ActSliced(r Runners, steps []Action) {
+	for _, step := range steps {
+		select {
+		case <-r.Ctx().Done():
+			return
+		default:
+		}
+		c := getReply[struct{}]()
+		r.Send(func() {
+			if recovers(r) {
+				defer replyPanic(func(pe *PanicError) { c <- struct{}{} })()
+			}
+			step()
+			c <- struct{}{}
+		})
+		select {
+		case <-r.Ctx().Done():
+			return
+		case <-c:
+			putReply(c)
+		}
+	}
+}
+
+// ActGetErrCtx is like ActGetErr but also stops waiting when ctx is done,
+// returning its error, wrapped in ErrTimeout if its deadline passed. The
+// action is skipped if ctx is done before it starts. With WithQuota, it
+// fails with ErrQuotaExceeded when the caller's tenant is over its rate.
+func ActGetErrCtx[T any](ctx context.Context, r Runners, action ActionReturnWithError[T]) (T, error) {
+	var zero T
+	if ctx.Err() != nil {
+		return zero, abandoned(ctx)
+	}
+	if err := admit(ctx, r); err != nil {
+		return zero, err
+	}
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	c := getReply[Result[T]]()
+	enqueued := time.Now()
+	rctx := sendAwaited(r, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- Err[T](pe) })()
+		}
+		if ctx.Err() != nil {
+			err := abandoned(ctx)
+			reportDropped(r, func() { _, _ = action() }, enqueued, err)
+			c <- Err[T](err)
+			return
+		}
+		_, end := callerSpan(ctx, r.Ctx(), r, enqueued)
+		res := ResultOf(action())
+		end(res.err)
+		c <- res
+	})
+	select {
+	case <-ctx.Done():
+		return zero, abandoned(ctx)
+	case <-rctx.Done():
+		return zero, stopped(rctx)
+	case p := <-c:
+		putReply(c)
+		return p.Unwrap()
+	}
+}
+
+// ActGetCtx is like ActGet but stops waiting when ctx is done, see
+// ActGetErrCtx.
+func ActGetCtx[T any](ctx context.Context, r Runners, action ActionReturn[T]) (T, error) {
+	return ActGetErrCtx(ctx, r, func() (T, error) {
+		return action(), nil
+	})
+}
+
+// ActErrCtx is like ActErr but stops waiting when ctx is done, see
+// ActGetErrCtx.
+func ActErrCtx(ctx context.Context, r Runners, action ActionErr) error {
+	_, err := ActGetErrCtx(ctx, r, func() (struct{}, error) {
+		return struct{}{}, action()
+	})
+	return err
+}
+
+// ActCtx is like Act but stops waiting when ctx is done, see ActGetErrCtx.
+// It returns the error of ctx or of the runner context, if any.
+func ActCtx(ctx context.Context, r Runners, action Action) error {
+	_, err := ActGetErrCtx(ctx, r, func() (struct{}, error) {
+		action()
+		return struct{}{}, nil
+	})
+	return err
 }
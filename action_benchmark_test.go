@@ -104,3 +104,44 @@ func BenchmarkComplexMutexGetSet(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkReadHeavyRWActable(b *testing.B) {
+	r := New()
+	require.NoError(b, r.Start(b.Context()))
+	a := NewRWActable(r, 0)
+	b.SetParallelism(runtime.NumCPU() * 10000)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			i++
+			if i%100 == 0 {
+				a.Set(i)
+				continue
+			}
+			_ = a.Get()
+		}
+	})
+}
+
+func BenchmarkReadHeavyMutex(b *testing.B) {
+	v := 0
+	var mut sync.RWMutex
+	b.SetParallelism(runtime.NumCPU() * 10000)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			i++
+			if i%100 == 0 {
+				mut.Lock()
+				v = i
+				mut.Unlock()
+				continue
+			}
+			mut.RLock()
+			_ = v
+			mut.RUnlock()
+		}
+	})
+}
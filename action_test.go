@@ -1,9 +1,12 @@
 package action_test
 
 import (
+	"context"
 	"errors"
 	"github.com/neonima/action"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sync"
 	"testing"
 	"time"
 )
@@ -220,3 +223,288 @@ func TestActGetErr(t *testing.T) {
 		})
 	}
 }
+
+func TestActGet_ReusedReplies(t *testing.T) {
+	t.Run("Should return each caller its own result", func(t *testing.T) {
+		r := action.New(action.WithChanSize(8))
+		require.NoError(t, r.Start(t.Context()))
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					assert.Equal(t, i, action.ActGet(r, func() int {
+						return i
+					}))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestTell(t *testing.T) {
+	t.Run("Should execute the action", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		n := 0
+		action.Tell(r, func() {
+			n++
+		})
+		require.Equal(t, 1, action.ActGet(r, func() int {
+			return n
+		}))
+	})
+	t.Run("Should not allocate", func(t *testing.T) {
+		r := action.New(action.WithChanSize(1024))
+		require.NoError(t, r.Start(t.Context()))
+		n := 0
+		incr := func() {
+			n++
+		}
+		allocs := testing.AllocsPerRun(1000, func() {
+			action.Tell(r, incr)
+		})
+		require.Zero(t, allocs)
+	})
+}
+
+func TestTellErr(t *testing.T) {
+	t.Run("Should execute the action", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := make(chan struct{})
+		require.NoError(t, action.TellErr(r, func() { close(ran) }))
+		<-ran
+	})
+	t.Run("Should fail once the runner is stopped", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		require.ErrorIs(t, action.TellErr(r, func() {}), action.ErrStopped)
+	})
+}
+
+func TestActGet2_Allocations(t *testing.T) {
+	t.Run("Should only allocate the action closure", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		get := func() (string, int) {
+			return "a", 1
+		}
+		action.ActGet2(r, get)
+		allocs := testing.AllocsPerRun(100, func() {
+			action.ActGet2(r, get)
+		})
+		require.LessOrEqual(t, allocs, 1.0)
+	})
+}
+
+func TestActWithContext(t *testing.T) {
+	t.Run("Should return the error of the action", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		err := errors.New("error")
+		require.ErrorIs(t, action.ActWithContext(t.Context(), r, func(ctx context.Context) error {
+			return err
+		}), err)
+	})
+	t.Run("Should give the action a context expiring after the action timeout", func(t *testing.T) {
+		r := action.New(action.WithActionTimeout(10 * time.Millisecond))
+		require.NoError(t, r.Start(t.Context()))
+		err := action.ActWithContext(t.Context(), r, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("Should give the action a context without deadline by default", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, action.ActWithContext(t.Context(), r, func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); ok {
+				return errors.New("unexpected deadline")
+			}
+			return nil
+		}))
+	})
+	t.Run("Should stop waiting once the caller context is done", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		release := make(chan struct{})
+		defer close(release)
+		action.Tell(r, func() {
+			<-release
+		})
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, action.ActWithContext(ctx, r, func(context.Context) error {
+			return nil
+		}), context.DeadlineExceeded)
+	})
+}
+
+func TestActForEach(t *testing.T) {
+	t.Run("Should iterate a snapshot outside the runner", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		items := []int{1, 2, 3}
+		var got []int
+		action.ActForEach(r, func() []int {
+			return append([]int(nil), items...)
+		}, func(v int) {
+			// Sending from the runner would deadlock.
+			action.Act(r, func() { items = append(items, v*10) })
+			got = append(got, v)
+		})
+		require.Equal(t, []int{1, 2, 3}, got)
+		require.Equal(t, []int{1, 2, 3, 10, 20, 30}, action.ActGet(r, func() []int { return items }))
+	})
+}
+
+type tenantKey struct{}
+
+type traceKey struct{}
+
+func TestActWithContext_ContextKeys(t *testing.T) {
+	t.Run("Should carry the selected caller values", func(t *testing.T) {
+		r := action.New(action.WithContextKeys(tenantKey{}))
+		require.NoError(t, r.Start(t.Context()))
+		ctx := context.WithValue(t.Context(), tenantKey{}, "acme")
+		ctx = context.WithValue(ctx, traceKey{}, "trace")
+		var tenant, trace any
+		require.NoError(t, action.ActWithContext(ctx, r, func(ctx context.Context) error {
+			tenant, trace = ctx.Value(tenantKey{}), ctx.Value(traceKey{})
+			return nil
+		}))
+		require.Equal(t, "acme", tenant)
+		require.Nil(t, trace)
+	})
+}
+
+func TestActWithContext_Cancellation(t *testing.T) {
+	t.Run("Should cancel the running action with the caller", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ctx, cancel := context.WithCancel(t.Context())
+		started := make(chan struct{})
+		aborted := make(chan error, 1)
+		go func() {
+			<-started
+			cancel()
+		}()
+		err := action.ActWithContext(ctx, r, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			aborted <- ctx.Err()
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, <-aborted, context.Canceled)
+	})
+	t.Run("Should skip an abandoned action", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		ran := false
+		require.ErrorIs(t, action.ActWithContext(ctx, r, func(context.Context) error {
+			ran = true
+			return nil
+		}), context.Canceled)
+		close(release)
+		require.False(t, action.ActGet(r, func() bool { return ran }))
+	})
+}
+
+func TestActSliced(t *testing.T) {
+	t.Run("Should interleave other actions between the steps", func(t *testing.T) {
+		r := action.New(action.WithChanSize(4))
+		require.NoError(t, r.Start(t.Context()))
+		var log []string
+		steps := []action.Action{
+			func() {
+				log = append(log, "step 1")
+				action.Tell(r, func() { log = append(log, "other") })
+			},
+			func() { log = append(log, "step 2") },
+		}
+		action.ActSliced(r, steps)
+		require.Equal(t, []string{"step 1", "other", "step 2"}, action.ActGet(r, func() []string { return log }))
+	})
+}
+
+func TestActCtx(t *testing.T) {
+	t.Run("Should return the action results", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		v, err := action.ActGetCtx(t.Context(), r, func() int { return 42 })
+		require.NoError(t, err)
+		require.Equal(t, 42, v)
+		errAction := errors.New("action")
+		require.ErrorIs(t, action.ActErrCtx(t.Context(), r, func() error { return errAction }), errAction)
+		require.NoError(t, action.ActCtx(t.Context(), r, func() {}))
+	})
+	t.Run("Should bound the call with the caller context", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		ran := false
+		_, err := action.ActGetCtx(ctx, r, func() bool {
+			ran = true
+			return true
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		close(release)
+		require.False(t, action.ActGet(r, func() bool { return ran }))
+	})
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("Should wrap ErrStopped when the runner stops", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		release := make(chan struct{})
+		defer close(release)
+		_, err := action.ActGetErr(r, func() (int, error) {
+			cancel()
+			<-release
+			return 1, nil
+		})
+		require.ErrorIs(t, err, action.ErrStopped)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("Should wrap ErrTimeout when the caller deadline passes", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		err := action.ActWithContext(ctx, r, func(context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		require.ErrorIs(t, err, action.ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("Should not wrap the errors of the action", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		err := action.ActErr(r, func() error { return context.DeadlineExceeded })
+		require.NotErrorIs(t, err, action.ErrTimeout)
+	})
+}
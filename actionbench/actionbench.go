@@ -0,0 +1,116 @@
+// Package actionbench helps deciding between an action runner and a lock for a
+// given workload by benchmarking both the same way action's own benchmarks do.
+//
+//	func BenchmarkStore(b *testing.B) {
+//		s := NewStore()
+//		actionbench.Compare(b, actionbench.Workload{
+//			Read:      func() { s.Get("k") },
+//			Write:     func() { s.Set("k", 1) },
+//			ReadRatio: 0.9,
+//		})
+//	}
+package actionbench
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/neonima/action"
+)
+
+// Workload describes the operations to benchmark. Writes never run
+// concurrently with other operations: they run on the runner or under the
+// write lock.
+type Workload struct {
+	// Read is a read-only operation on the shared state.
+	Read func()
+	// Write is a mutating operation on the shared state.
+	Write func()
+	// ReadRatio is the share of operations that are reads, from 0 to 1.
+	ReadRatio float64
+	// Cost adds that many iterations of busy work to every operation, to
+	// simulate heavier payloads.
+	Cost int
+	// Parallelism is the number of goroutines per CPU issuing operations.
+	// Default is 1.
+	Parallelism int
+	// ChanSize is the runner buffer size, see action.WithChanSize.
+	ChanSize int
+}
+
+var sink atomic.Uint64
+
+func (w Workload) do(f func()) {
+	if f != nil {
+		f()
+	}
+	if w.Cost <= 0 {
+		return
+	}
+	var n uint64
+	for i := range w.Cost {
+		n += uint64(i)
+	}
+	sink.Add(n)
+}
+
+func (w Workload) run(b *testing.B, read, write func()) {
+	if w.Parallelism > 0 {
+		b.SetParallelism(w.Parallelism)
+	}
+	seed := time.Now().UnixNano()
+	var seq atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rnd := rand.New(rand.NewSource(seed + seq.Add(1)))
+		for pb.Next() {
+			if rnd.Float64() < w.ReadRatio {
+				read()
+			} else {
+				write()
+			}
+		}
+	})
+}
+
+// Runner benchmarks the workload with every operation executed by a runner.
+func Runner(b *testing.B, w Workload) {
+	r := action.New(action.WithChanSize(w.ChanSize))
+	if err := r.Start(b.Context()); err != nil {
+		b.Fatal(err)
+	}
+	read := func() { w.do(w.Read) }
+	write := func() { w.do(w.Write) }
+	w.run(b, func() {
+		action.Act(r, read)
+	}, func() {
+		action.Act(r, write)
+	})
+}
+
+// Mutex benchmarks the workload with operations protected by a sync.RWMutex.
+func Mutex(b *testing.B, w Workload) {
+	var mtx sync.RWMutex
+	w.run(b, func() {
+		mtx.RLock()
+		defer mtx.RUnlock()
+		w.do(w.Read)
+	}, func() {
+		mtx.Lock()
+		defer mtx.Unlock()
+		w.do(w.Write)
+	})
+}
+
+// Compare runs the Runner and Mutex benchmarks as sub-benchmarks.
+func Compare(b *testing.B, w Workload) {
+	b.Run("runner", func(b *testing.B) {
+		Runner(b, w)
+	})
+	b.Run("mutex", func(b *testing.B) {
+		Mutex(b, w)
+	})
+}
@@ -0,0 +1,52 @@
+package actionbench_test
+
+import (
+	"github.com/neonima/action/actionbench"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	t.Run("Should run reads and writes on the runner", func(t *testing.T) {
+		var reads, writes atomic.Int64
+		res := testing.Benchmark(func(b *testing.B) {
+			actionbench.Runner(b, actionbench.Workload{
+				Read:      func() { reads.Add(1) },
+				Write:     func() { writes.Add(1) },
+				ReadRatio: 0.5,
+			})
+		})
+		require.NotZero(t, res.N)
+		require.NotZero(t, reads.Load())
+		require.NotZero(t, writes.Load())
+	})
+}
+
+func TestMutex(t *testing.T) {
+	t.Run("Should only run reads with a ratio of 1", func(t *testing.T) {
+		var reads, writes atomic.Int64
+		res := testing.Benchmark(func(b *testing.B) {
+			actionbench.Mutex(b, actionbench.Workload{
+				Read:      func() { reads.Add(1) },
+				Write:     func() { writes.Add(1) },
+				ReadRatio: 1,
+				Cost:      10,
+			})
+		})
+		require.NotZero(t, res.N)
+		require.NotZero(t, reads.Load())
+		require.Zero(t, writes.Load())
+	})
+}
+
+func BenchmarkCompare(b *testing.B) {
+	m := make(map[int]int)
+	i := 0
+	actionbench.Compare(b, actionbench.Workload{
+		Read:      func() { _ = m[i] },
+		Write:     func() { i++; m[i] = i },
+		ReadRatio: 0.9,
+		Cost:      100,
+	})
+}
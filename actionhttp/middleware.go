@@ -0,0 +1,62 @@
+// Package actionhttp runs net/http handlers through an action runner, so they
+// can share in-memory state without locks.
+package actionhttp
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/neonima/action"
+)
+
+const (
+	pending int32 = iota
+	running
+	abandoned
+)
+
+// Middleware returns a middleware executing the wrapped handler on r, one
+// request at a time. Keep handlers short: slow I/O holds up every other
+// request on the runner.
+//
+// Requests whose context is done before their turn are skipped. If the
+// runner is stopped, or stops first, the middleware answers 503 Service
+// Unavailable.
+func Middleware(r action.Runners) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var state atomic.Int32
+			done := make(chan struct{})
+			err := action.TellErr(r, func() {
+				defer close(done)
+				if !state.CompareAndSwap(pending, running) {
+					return
+				}
+				next.ServeHTTP(w, req)
+			})
+			if err != nil {
+				unavailable(w)
+				return
+			}
+			select {
+			case <-done:
+				return
+			case <-req.Context().Done():
+				if state.CompareAndSwap(pending, abandoned) {
+					return
+				}
+			case <-r.Ctx().Done():
+				if state.CompareAndSwap(pending, abandoned) {
+					unavailable(w)
+					return
+				}
+			}
+			// The handler is running and still owns the response writer.
+			<-done
+		})
+	}
+}
+
+func unavailable(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,86 @@
+package actionhttp_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actionhttp"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Run("Should serialize handlers on the runner", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		hits := 0
+		h := actionhttp.Middleware(r)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			hits++
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, 50, action.ActGet(r, func() int {
+			return hits
+		}))
+	})
+	t.Run("Should skip requests abandoned before their turn", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		release := make(chan struct{})
+		action.Tell(r, func() {
+			<-release
+		})
+		called := false
+		h := actionhttp.Middleware(r)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = true
+		}))
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		close(release)
+		require.False(t, action.ActGet(r, func() bool {
+			return called
+		}))
+	})
+	t.Run("Should answer 503 once the runner is stopped", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		started, release := make(chan struct{}), make(chan struct{})
+		action.Tell(r, func() {
+			close(started)
+			<-release
+		})
+		<-started
+		h := actionhttp.Middleware(r)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+		cancel()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		close(release)
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+	t.Run("Should answer 503 to requests arriving after the runner is closed", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		called := false
+		h := actionhttp.Middleware(r)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = true
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.False(t, called)
+	})
+}
@@ -0,0 +1,73 @@
+// Package actionotel traces action runners with OpenTelemetry. It lives in
+// its own module so the action package stays free of the OpenTelemetry
+// dependency.
+package actionotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/neonima/action"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SpanAct is the span of an action run with ActWithContext or
+	// ActGetErrCtx, child of the caller's span.
+	SpanAct = "action.act"
+	// EventEnqueued is the event of SpanAct marking when the action was
+	// sent, its queue wait being the time until the span start.
+	EventEnqueued = "action.enqueued"
+	// AttrQueueWait is the attribute of SpanAct holding how long the
+	// action waited in the queue, in seconds.
+	AttrQueueWait = "action.queue_wait_seconds"
+)
+
+// WithTracing traces the runner with tracer: every action gets an
+// action.SpanExecute span, and the actions of ActWithContext and
+// ActGetErrCtx a SpanAct span continuing the trace of the caller's
+// context, with the time spent in the queue. It can be combined with
+// other action.WithTelemetry providers.
+func WithTracing(tracer trace.Tracer) func(*action.Runner) {
+	return action.WithTelemetry(&tracing{tracer: tracer})
+}
+
+type tracing struct {
+	tracer trace.Tracer
+}
+
+var (
+	_ action.Telemetry    = (*tracing)(nil)
+	_ action.CallerTracer = (*tracing)(nil)
+)
+
+func (t *tracing) Count(string, int64) {}
+
+func (t *tracing) Observe(string, float64) {}
+
+func (t *tracing) StartSpan(ctx context.Context, name string) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, end(span)
+}
+
+func (t *tracing) StartCallerSpan(caller, ctx context.Context, enqueued time.Time) (context.Context, func(error)) {
+	ctx = trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(caller))
+	ctx, span := t.tracer.Start(ctx, SpanAct, trace.WithSpanKind(trace.SpanKindConsumer))
+	span.AddEvent(EventEnqueued, trace.WithTimestamp(enqueued))
+	span.SetAttributes(attribute.Float64(AttrQueueWait, time.Since(enqueued).Seconds()))
+	return ctx, end(span)
+}
+
+// end returns the function ending span with an error status if the action
+// failed.
+func end(span trace.Span) func(error) {
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,80 @@
+package actionotel_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actionotel"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func newTracer() (trace.Tracer, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test"), rec
+}
+
+func TestWithTracing(t *testing.T) {
+	t.Run("Should start a span per action", func(t *testing.T) {
+		tracer, rec := newTracer()
+		r := action.New(actionotel.WithTracing(tracer))
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() {})
+		action.Act(r, func() {})
+		require.NoError(t, r.Close())
+		spans := rec.Ended()
+		require.Len(t, spans, 2)
+		require.Equal(t, action.SpanExecute, spans[0].Name())
+	})
+	t.Run("Should continue the caller's trace", func(t *testing.T) {
+		tracer, rec := newTracer()
+		r := action.New(actionotel.WithTracing(tracer))
+		require.NoError(t, r.Start(t.Context()))
+		ctx, parent := tracer.Start(t.Context(), "caller")
+		errAction := errors.New("action")
+		var inner trace.SpanContext
+		err := action.ActWithContext(ctx, r, func(ctx context.Context) error {
+			inner = trace.SpanContextFromContext(ctx)
+			return errAction
+		})
+		require.ErrorIs(t, err, errAction)
+		parent.End()
+		require.NoError(t, r.Close())
+		var act sdktrace.ReadOnlySpan
+		for _, s := range rec.Ended() {
+			if s.Name() == actionotel.SpanAct {
+				act = s
+			}
+		}
+		require.NotNil(t, act)
+		require.Equal(t, parent.SpanContext().TraceID(), act.SpanContext().TraceID())
+		require.Equal(t, parent.SpanContext().SpanID(), act.Parent().SpanID())
+		require.Equal(t, act.SpanContext(), inner)
+		require.Equal(t, codes.Error, act.Status().Code)
+		require.Equal(t, actionotel.EventEnqueued, act.Events()[0].Name)
+		require.Equal(t, actionotel.AttrQueueWait, string(act.Attributes()[0].Key))
+	})
+	t.Run("Should trace ActGetErrCtx", func(t *testing.T) {
+		tracer, rec := newTracer()
+		r := action.New(actionotel.WithTracing(tracer))
+		require.NoError(t, r.Start(t.Context()))
+		ctx, parent := tracer.Start(t.Context(), "caller")
+		v, err := action.ActGetCtx(ctx, r, func() int { return 1 })
+		require.NoError(t, err)
+		require.Equal(t, 1, v)
+		parent.End()
+		require.NoError(t, r.Close())
+		found := false
+		for _, s := range rec.Ended() {
+			if s.Name() == actionotel.SpanAct {
+				found = true
+				require.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+			}
+		}
+		require.True(t, found)
+	})
+}
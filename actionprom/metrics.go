@@ -0,0 +1,181 @@
+// Package actionprom exposes the measurements of action runners as
+// Prometheus metrics. It lives in its own module so the action package stays
+// free of the Prometheus dependency.
+package actionprom
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/neonima/action"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LabelRunner is the label carrying the runner name on every metric.
+const LabelRunner = "runner"
+
+// Metrics holds the collectors shared by the runners of a registerer.
+type Metrics struct {
+	sent     *prometheus.CounterVec
+	executed *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	timeouts *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	depth    *queueDepth
+}
+
+// NewMetrics registers the runner metrics with reg, or reuses the ones
+// already registered: actions sent and executed, action duration, errors of
+// the hooks and captured panics, actions skipped after their caller's
+// deadline, and queue depth. It panics, like prometheus.MustRegister, if the
+// metrics cannot be registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	labels := []string{LabelRunner}
+	return &Metrics{
+		sent: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "action_sent_total",
+			Help: "Number of actions sent to the runner.",
+		}, labels)),
+		executed: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "action_executed_total",
+			Help: "Number of actions executed by the runner.",
+		}, labels)),
+		errors: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "action_errors_total",
+			Help: "Number of actions whose hooks failed or that panicked.",
+		}, labels)),
+		timeouts: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "action_timeouts_total",
+			Help: "Number of actions skipped because their caller's deadline passed.",
+		}, labels)),
+		duration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "action_duration_seconds",
+			Help:    "Duration of the actions, hooks included.",
+			Buckets: prometheus.ExponentialBuckets(1e-6, 4, 12),
+		}, labels)),
+		depth: register(reg, &queueDepth{
+			desc: prometheus.NewDesc("action_queue_depth",
+				"Number of actions waiting in the runner queues.", labels, nil),
+			runners: make(map[string]*action.Runner),
+		}),
+	}
+}
+
+// Runner returns an option reporting the metrics of the runner, labeled
+// with name. Runners must have distinct names: a runner bound under the
+// name of another one replaces it in the queue depth. A stopped runner is
+// left out of the queue depth until it is sent to again, see Runner.Reset.
+func (m *Metrics) Runner(name string) func(*action.Runner) {
+	return func(r *action.Runner) {
+		t := &telemetry{
+			sent:     m.sent.WithLabelValues(name),
+			executed: m.executed.WithLabelValues(name),
+			errors:   m.errors.WithLabelValues(name),
+			timeouts: m.timeouts.WithLabelValues(name),
+			duration: m.duration.WithLabelValues(name),
+		}
+		t.bind = func() { m.depth.bind(name, r, &t.bound) }
+		action.WithTelemetry(t)(r)
+		t.bind()
+	}
+}
+
+// WithMetrics reports the metrics of the runner to reg, labeled with name,
+// see NewMetrics and Metrics.Runner.
+func WithMetrics(reg prometheus.Registerer, name string) func(*action.Runner) {
+	return NewMetrics(reg).Runner(name)
+}
+
+// queueDepth collects the queue depth of the bound runners.
+type queueDepth struct {
+	desc    *prometheus.Desc
+	mu      sync.Mutex
+	runners map[string]*action.Runner
+}
+
+// bind collects the queue depth of r until it stops, then clears bound.
+func (q *queueDepth) bind(name string, r *action.Runner, bound *atomic.Bool) {
+	bound.Store(true)
+	q.mu.Lock()
+	q.runners[name] = r
+	q.mu.Unlock()
+	context.AfterFunc(r.DoneCtx(), func() {
+		q.mu.Lock()
+		if q.runners[name] == r {
+			delete(q.runners, name)
+		}
+		q.mu.Unlock()
+		bound.Store(false)
+	})
+}
+
+func (q *queueDepth) Describe(ch chan<- *prometheus.Desc) {
+	ch <- q.desc
+}
+
+func (q *queueDepth) Collect(ch chan<- prometheus.Metric) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for name, r := range q.runners {
+		ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, float64(r.Len()), name)
+	}
+}
+
+// register registers c with reg, returning the collector already
+// registered instead if there is one.
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// telemetry implements action.Telemetry over the collectors of a runner.
+type telemetry struct {
+	sent     prometheus.Counter
+	executed prometheus.Counter
+	errors   prometheus.Counter
+	timeouts prometheus.Counter
+	duration prometheus.Observer
+	// bind binds the runner to the queue depth again once restarted.
+	bind  func()
+	bound atomic.Bool
+}
+
+func (t *telemetry) Count(name string, delta int64) {
+	switch name {
+	case action.MetricSent:
+		if !t.bound.Load() {
+			t.bind()
+		}
+		t.sent.Add(float64(delta))
+	case action.MetricExecuted:
+		t.executed.Add(float64(delta))
+	case action.MetricTimeout:
+		t.timeouts.Add(float64(delta))
+	}
+}
+
+func (t *telemetry) Observe(name string, value float64) {
+	if name == action.MetricDuration {
+		t.duration.Observe(value)
+	}
+}
+
+func (t *telemetry) StartSpan(ctx context.Context, _ string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		if err != nil {
+			t.errors.Inc()
+		}
+	}
+}
+
+var _ action.Telemetry = (*telemetry)(nil)
@@ -0,0 +1,83 @@
+package actionprom_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actionprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("Should expose the runner metrics", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		r := action.New(actionprom.WithMetrics(reg, "orders"))
+		require.NoError(t, r.Start(t.Context()))
+		for range 3 {
+			action.Act(r, func() {})
+		}
+		require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP action_sent_total Number of actions sent to the runner.
+# TYPE action_sent_total counter
+action_sent_total{runner="orders"} 3
+# HELP action_queue_depth Number of actions waiting in the runner queues.
+# TYPE action_queue_depth gauge
+action_queue_depth{runner="orders"} 0
+`), "action_sent_total", "action_queue_depth"))
+		require.Equal(t, 1, testutil.CollectAndCount(reg, "action_duration_seconds"))
+	})
+	t.Run("Should share the collectors between runners", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		errHook := errors.New("hook")
+		a := action.New(actionprom.WithMetrics(reg, "a"))
+		b := action.New(actionprom.WithMetrics(reg, "b"), action.WithHook(func(ctx context.Context) error {
+			return errHook
+		}))
+		require.NoError(t, a.Start(t.Context()))
+		require.NoError(t, b.Start(t.Context()))
+		action.Act(a, func() {})
+		b.Send(func() {})
+		<-b.Done()
+		require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP action_errors_total Number of actions whose hooks failed or that panicked.
+# TYPE action_errors_total counter
+action_errors_total{runner="a"} 0
+action_errors_total{runner="b"} 1
+`), "action_errors_total"))
+	})
+	t.Run("Should bind runners again without panicking", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		opt := actionprom.WithMetrics(reg, "jobs")
+		action.New(opt)
+		r := action.New(actionprom.WithMetrics(reg, "jobs"))
+		m := actionprom.NewMetrics(reg)
+		other := action.New(m.Runner("other"))
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, other.Start(t.Context()))
+		require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP action_queue_depth Number of actions waiting in the runner queues.
+# TYPE action_queue_depth gauge
+action_queue_depth{runner="jobs"} 0
+action_queue_depth{runner="other"} 0
+`), "action_queue_depth"))
+	})
+	t.Run("Should leave stopped runners out of the queue depth", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		r := action.New(actionprom.WithMetrics(reg, "short"))
+		require.NoError(t, r.Start(t.Context()))
+		require.Equal(t, 1, testutil.CollectAndCount(reg, "action_queue_depth"))
+		require.NoError(t, r.Close())
+		require.Eventually(t, func() bool {
+			return testutil.CollectAndCount(reg, "action_queue_depth") == 0
+		}, time.Second, time.Millisecond)
+		require.NoError(t, r.Reset())
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() {})
+		require.Equal(t, 1, testutil.CollectAndCount(reg, "action_queue_depth"))
+	})
+}
@@ -0,0 +1,169 @@
+package actiontest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neonima/action"
+)
+
+// ErrChaos is returned by hooks failing on purpose, see ChaosHook.
+var ErrChaos = errors.New("actiontest: injected failure")
+
+// Chaos configures the faults injected by ChaosRunner and ChaosHook. Rates
+// are probabilities from 0 to 1.
+type Chaos struct {
+	// Seed makes the injected faults reproducible. 0 picks a time based seed,
+	// reported by ChaosRunner.Seed.
+	Seed int64
+	// Delay is how long a delayed action sleeps on the runner before running.
+	Delay time.Duration
+	// DelayRate is the probability of an action being delayed.
+	DelayRate float64
+	// DropRate is the probability of an action being silently dropped. The
+	// Act helpers waiting on a dropped action only return once the runner
+	// context is done.
+	DropRate float64
+	// HookErrorRate is the probability of ChaosHook returning ErrChaos.
+	HookErrorRate float64
+	// RestartRate is the probability of the runner being restarted when an
+	// action is sent, losing its queued actions. It needs a runner that can
+	// be reset, such as *action.Runner, started by ChaosRunner.Start. Actions
+	// sent while it restarts are dropped. Supervised runners are restarted by
+	// their Supervisor after failing with ChaosHook instead.
+	RestartRate float64
+}
+
+type lockedRand struct {
+	mu   sync.Mutex
+	rnd  *rand.Rand
+	seed int64
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &lockedRand{rnd: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+func (l *lockedRand) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rnd.Float64() < rate
+}
+
+// ChaosRunner wraps an action.Runners and delays, drops sent actions or
+// restarts the runner according to its Chaos configuration.
+type ChaosRunner struct {
+	action.Runners
+	chaos Chaos
+	rnd   *lockedRand
+
+	ctx        atomic.Pointer[context.Context]
+	restarting atomic.Bool
+	restarts   atomic.Int64
+}
+
+// resetter is implemented by the runners ChaosRunner can restart.
+type resetter interface {
+	Stop()
+	Done() <-chan struct{}
+	Reset() error
+}
+
+var _ action.Runners = (*ChaosRunner)(nil)
+
+// NewChaosRunner returns r wrapped to inject the faults described by c.
+func NewChaosRunner(r action.Runners, c Chaos) *ChaosRunner {
+	return &ChaosRunner{
+		Runners: r,
+		chaos:   c,
+		rnd:     newLockedRand(c.Seed),
+	}
+}
+
+// Seed returns the seed used to inject faults, so a failing run can be
+// reproduced by setting it in Chaos.
+func (r *ChaosRunner) Seed() int64 {
+	return r.rnd.seed
+}
+
+// Start starts the wrapped runner, keeping ctx to restart it.
+func (r *ChaosRunner) Start(ctx context.Context) error {
+	if err := r.Runners.Start(ctx); err != nil {
+		return err
+	}
+	r.ctx.Store(&ctx)
+	return nil
+}
+
+// Restarts returns how many times the runner was restarted.
+func (r *ChaosRunner) Restarts() int {
+	return int(r.restarts.Load())
+}
+
+// Send enqueues the action, unless it is dropped.
+func (r *ChaosRunner) Send(a action.Action) {
+	if r.rnd.hit(r.chaos.RestartRate) {
+		r.restart()
+	}
+	if r.rnd.hit(r.chaos.DropRate) {
+		return
+	}
+	if r.rnd.hit(r.chaos.DelayRate) {
+		d := r.chaos.Delay
+		r.send(func() {
+			time.Sleep(d)
+			a()
+		})
+		return
+	}
+	r.send(a)
+}
+
+func (r *ChaosRunner) send(a action.Action) {
+	if r.chaos.RestartRate > 0 {
+		_ = action.TellErr(r.Runners, a)
+		return
+	}
+	r.Runners.Send(a)
+}
+
+// restart stops, resets and starts the runner again on its own goroutine,
+// as Send may be called from an action of the runner.
+func (r *ChaosRunner) restart() {
+	rs, ok := r.Runners.(resetter)
+	ctx := r.ctx.Load()
+	if !ok || ctx == nil || !r.restarting.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer r.restarting.Store(false)
+		rs.Stop()
+		<-rs.Done()
+		if rs.Reset() == nil && r.Runners.Start(*ctx) == nil {
+			r.restarts.Add(1)
+		}
+	}()
+}
+
+// ChaosHook returns a hook for action.WithHook failing with ErrChaos at the
+// configured HookErrorRate, which stops the runner. Pass a ChaosRunner Seed
+// in c to fail along with its faults reproducibly.
+func ChaosHook(c Chaos) func(context.Context) error {
+	rnd := newLockedRand(c.Seed)
+	return func(context.Context) error {
+		if rnd.hit(c.HookErrorRate) {
+			return ErrChaos
+		}
+		return nil
+	}
+}
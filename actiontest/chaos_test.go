@@ -0,0 +1,97 @@
+package actiontest_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestChaosRunner(t *testing.T) {
+	t.Run("Should pass actions through without faults", func(t *testing.T) {
+		r := actiontest.NewChaosRunner(actiontest.NewSyncRunner(), actiontest.Chaos{})
+		n := 0
+		for range 10 {
+			action.Act(r, func() { n++ })
+		}
+		require.Equal(t, 10, n)
+	})
+	t.Run("Should drop actions", func(t *testing.T) {
+		r := actiontest.NewChaosRunner(actiontest.NewSyncRunner(), actiontest.Chaos{Seed: 1, DropRate: 0.5})
+		n := 0
+		for range 100 {
+			r.Send(func() { n++ })
+		}
+		require.Greater(t, n, 0)
+		require.Less(t, n, 100)
+	})
+	t.Run("Should report a time based seed reproducing the faults", func(t *testing.T) {
+		drops := func(r *actiontest.ChaosRunner) []bool {
+			var dropped []bool
+			for range 100 {
+				ran := false
+				r.Send(func() { ran = true })
+				dropped = append(dropped, !ran)
+			}
+			return dropped
+		}
+		r := actiontest.NewChaosRunner(actiontest.NewSyncRunner(), actiontest.Chaos{DropRate: 0.5})
+		require.NotZero(t, r.Seed())
+		again := actiontest.NewChaosRunner(actiontest.NewSyncRunner(), actiontest.Chaos{Seed: r.Seed(), DropRate: 0.5})
+		require.Equal(t, r.Seed(), again.Seed())
+		require.Equal(t, drops(r), drops(again))
+	})
+	t.Run("Should delay actions", func(t *testing.T) {
+		r := actiontest.NewChaosRunner(actiontest.NewSyncRunner(), actiontest.Chaos{
+			Delay:     10 * time.Millisecond,
+			DelayRate: 1,
+		})
+		start := time.Now()
+		action.Act(r, func() {})
+		require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	})
+	t.Run("Should unblock callers of dropped actions once the runner stops", func(t *testing.T) {
+		inner := action.New()
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.NoError(t, inner.Start(ctx))
+		r := actiontest.NewChaosRunner(inner, actiontest.Chaos{DropRate: 1})
+		err := action.ActErr(r, func() error { return nil })
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("Should restart the runner", func(t *testing.T) {
+		inner := action.New()
+		r := actiontest.NewChaosRunner(inner, actiontest.Chaos{RestartRate: 1})
+		require.NoError(t, r.Start(t.Context()))
+		defer inner.Close()
+		r.Send(func() {})
+		require.Eventually(t, func() bool { return r.Restarts() == 1 }, time.Second, time.Millisecond)
+		require.NoError(t, inner.Ctx().Err())
+		require.Equal(t, 42, action.ActGet(inner, func() int { return 42 }))
+	})
+	t.Run("Should not restart a runner started directly", func(t *testing.T) {
+		inner := action.New()
+		require.NoError(t, inner.Start(t.Context()))
+		r := actiontest.NewChaosRunner(inner, actiontest.Chaos{RestartRate: 1})
+		require.Equal(t, 42, action.ActGet(r, func() int { return 42 }))
+		require.Zero(t, r.Restarts())
+	})
+}
+
+func TestChaosHook(t *testing.T) {
+	t.Run("Should stop the runner with ErrChaos", func(t *testing.T) {
+		r := action.New(action.WithHook(actiontest.ChaosHook(actiontest.Chaos{HookErrorRate: 1})))
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {})
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), actiontest.ErrChaos)
+	})
+	t.Run("Should never fail with a zero rate", func(t *testing.T) {
+		h := actiontest.ChaosHook(actiontest.Chaos{})
+		for range 100 {
+			require.NoError(t, h(t.Context()))
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package actiontest
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/neonima/action"
+)
+
+// GuardRunner wraps an action.Runners and detects changes made to guarded
+// state outside of its actions. See WithGuard.
+type GuardRunner struct {
+	action.Runners
+	t      testing.TB
+	count  atomic.Int64
+	guards []guarded
+}
+
+type guarded struct {
+	ptr reflect.Value
+	sum uint64
+}
+
+var _ action.Runners = (*GuardRunner)(nil)
+
+// WithGuard returns r wrapped so that the state registered with Guard is
+// checksummed between actions, on the runner's goroutine. A change that did
+// not happen inside an action fails the test with the sequence number and
+// origin of the first action observing it. It helps making sure all access
+// goes through the runner while migrating code to it.
+//
+// The state is compared with its %#v formatting: pointers are compared by
+// address, not by what they point to.
+func WithGuard(t testing.TB, r action.Runners) *GuardRunner {
+	return &GuardRunner{
+		Runners: r,
+		t:       t,
+	}
+}
+
+// Guard registers the state ptr points to. The runner must be started.
+func (r *GuardRunner) Guard(ptr any) {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		panic(fmt.Sprintf("actiontest: Guard needs a non-nil pointer, got %T", ptr))
+	}
+	action.Act(r.Runners, func() {
+		r.guards = append(r.guards, guarded{ptr: v, sum: checksum(v)})
+	})
+}
+
+// Send enqueues the action surrounded by the guarded state checks.
+func (r *GuardRunner) Send(a action.Action) {
+	n := r.count.Add(1)
+	from := caller()
+	r.Runners.Send(func() {
+		for i := range r.guards {
+			g := &r.guards[i]
+			if sum := checksum(g.ptr); sum != g.sum {
+				g.sum = sum
+				r.t.Errorf("actiontest: %s modified outside of an action, observed by action #%d sent from %s", g.ptr.Type().Elem(), n, from)
+			}
+		}
+		a()
+		for i := range r.guards {
+			r.guards[i].sum = checksum(r.guards[i].ptr)
+		}
+	})
+}
+
+func checksum(ptr reflect.Value) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%#v", ptr.Elem().Interface())
+	return h.Sum64()
+}
@@ -0,0 +1,36 @@
+package actiontest_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWithGuard(t *testing.T) {
+	t.Run("Should accept changes made inside actions", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		r := actiontest.WithGuard(rt, actiontest.NewSyncRunner())
+		counts := map[string]int{}
+		r.Guard(&counts)
+		action.Act(r, func() { counts["a"]++ })
+		action.Act(r, func() { counts["b"]++ })
+		require.Zero(t, rt.errors)
+	})
+	t.Run("Should fail on changes made outside actions", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		r := actiontest.WithGuard(rt, actiontest.NewSyncRunner())
+		balance := 10
+		r.Guard(&balance)
+		action.Act(r, func() { balance -= 5 })
+		balance = 100
+		action.Act(r, func() {})
+		require.Equal(t, 1, rt.errors)
+		action.Act(r, func() {})
+		require.Equal(t, 1, rt.errors)
+	})
+	t.Run("Should reject non pointers", func(t *testing.T) {
+		r := actiontest.WithGuard(t, actiontest.NewSyncRunner())
+		require.Panics(t, func() { r.Guard(1) })
+	})
+}
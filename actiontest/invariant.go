@@ -0,0 +1,66 @@
+package actiontest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/neonima/action"
+)
+
+// InvariantRunner wraps an action.Runners and checks an invariant after every
+// action. See WithInvariant.
+type InvariantRunner struct {
+	action.Runners
+	t         testing.TB
+	invariant func() error
+	count     atomic.Int64
+	violated  atomic.Bool
+}
+
+var _ action.Runners = (*InvariantRunner)(nil)
+
+// WithInvariant returns r wrapped so that invariant runs after every action,
+// on the runner's goroutine. The first violation fails the test with the
+// action's sequence number and the location it was sent from; later actions
+// are no longer checked.
+func WithInvariant(t testing.TB, r action.Runners, invariant func() error) *InvariantRunner {
+	return &InvariantRunner{
+		Runners:   r,
+		t:         t,
+		invariant: invariant,
+	}
+}
+
+// Send enqueues the action followed by an invariant check.
+func (r *InvariantRunner) Send(a action.Action) {
+	n := r.count.Add(1)
+	from := caller()
+	r.Runners.Send(func() {
+		a()
+		if r.violated.Load() {
+			return
+		}
+		if err := r.invariant(); err != nil {
+			r.violated.Store(true)
+			r.t.Errorf("actiontest: invariant violated by action #%d sent from %s: %v", n, from, err)
+		}
+	})
+}
+
+// caller returns the first frame outside of the action package.
+func caller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "github.com/neonima/action.") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package actiontest_test
+
+import (
+	"errors"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWithInvariant(t *testing.T) {
+	t.Run("Should check the invariant after each action", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		checks := 0
+		r := actiontest.WithInvariant(rt, actiontest.NewSyncRunner(), func() error {
+			checks++
+			return nil
+		})
+		action.Act(r, func() {})
+		action.Act(r, func() {})
+		require.Equal(t, 2, checks)
+		require.Zero(t, rt.errors)
+	})
+	t.Run("Should fail once on the action breaking the invariant", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		balance := 10
+		r := actiontest.WithInvariant(rt, actiontest.NewSyncRunner(), func() error {
+			if balance < 0 {
+				return errors.New("negative balance")
+			}
+			return nil
+		})
+		action.Act(r, func() { balance -= 5 })
+		require.Zero(t, rt.errors)
+		action.Act(r, func() { balance -= 10 })
+		action.Act(r, func() { balance -= 10 })
+		require.Equal(t, 1, rt.errors)
+	})
+	t.Run("Should work on a real runner", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		inner := action.New()
+		require.NoError(t, inner.Start(t.Context()))
+		r := actiontest.WithInvariant(rt, inner, func() error {
+			return errors.New("always broken")
+		})
+		action.Act(r, func() {})
+		// The check runs after the action, flush it with a second one.
+		action.Act(inner, func() {})
+		require.Equal(t, 1, rt.errors)
+	})
+}
@@ -0,0 +1,126 @@
+package actiontest
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/neonima/action"
+)
+
+// ShuffleRunner is an action.Runners executing pending actions one at a time
+// in a seeded random order instead of FIFO. It shakes out code relying on an
+// ordering the runner never promised, such as two goroutines' actions
+// running in the order they were sent.
+type ShuffleRunner struct {
+	mu        sync.Mutex
+	pending   []action.Action
+	rnd       *rand.Rand
+	seed      int64
+	wake      chan struct{}
+	isStarted atomic.Bool
+	ctx       context.Context
+	done      chan struct{}
+}
+
+var _ action.Runners = (*ShuffleRunner)(nil)
+
+// NewShuffleRunner returns a ShuffleRunner using the given seed, or a time
+// based one if seed is 0. The seed is logged if the test fails so the run
+// can be reproduced.
+func NewShuffleRunner(t testing.TB, seed int64) *ShuffleRunner {
+	t.Helper()
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := &ShuffleRunner{
+		rnd:  rand.New(rand.NewSource(seed)),
+		seed: seed,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("actiontest: shuffle runner seed %d", seed)
+		}
+	})
+	return r
+}
+
+// Seed returns the seed used to order actions.
+func (r *ShuffleRunner) Seed() int64 {
+	return r.seed
+}
+
+// Start starts the runner on a separated goroutine
+func (r *ShuffleRunner) Start(ctx context.Context) error {
+	if ctx == nil {
+		return action.ErrNilContext
+	}
+	if !r.isStarted.CompareAndSwap(false, true) {
+		return action.ErrAlreadyStarted
+	}
+	r.ctx = ctx
+	go r.start(ctx)
+	return nil
+}
+
+func (r *ShuffleRunner) start(ctx context.Context) {
+	defer close(r.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		}
+		for {
+			// Let concurrent senders catch up so there is something to shuffle.
+			runtime.Gosched()
+			a, ok := r.next()
+			if !ok {
+				break
+			}
+			a()
+		}
+	}
+}
+
+func (r *ShuffleRunner) next() (action.Action, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.pending)
+	if n == 0 {
+		return nil, false
+	}
+	i := r.rnd.Intn(n)
+	a := r.pending[i]
+	r.pending[i] = r.pending[n-1]
+	r.pending[n-1] = nil
+	r.pending = r.pending[:n-1]
+	return a, true
+}
+
+// Send adds the action to the pending set. It never blocks.
+func (r *ShuffleRunner) Send(a action.Action) {
+	r.mu.Lock()
+	r.pending = append(r.pending, a)
+	r.mu.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Ctx returns the context
+func (r *ShuffleRunner) Ctx() context.Context {
+	return r.ctx
+}
+
+// Done returns a channel closed when the runner is stopped.
+func (r *ShuffleRunner) Done() <-chan struct{} {
+	return r.done
+}
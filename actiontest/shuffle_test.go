@@ -0,0 +1,48 @@
+package actiontest_test
+
+import (
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func runShuffled(t *testing.T, seed int64, n int) []int {
+	r := actiontest.NewShuffleRunner(t, seed)
+	var order []int
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range n {
+		r.Send(func() {
+			order = append(order, i)
+			wg.Done()
+		})
+	}
+	require.NoError(t, r.Start(t.Context()))
+	wg.Wait()
+	return order
+}
+
+func sequence(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func TestShuffleRunner(t *testing.T) {
+	t.Run("Should execute every action", func(t *testing.T) {
+		require.ElementsMatch(t, sequence(50), runShuffled(t, 42, 50))
+	})
+	t.Run("Should not preserve the send order", func(t *testing.T) {
+		require.NotEqual(t, sequence(50), runShuffled(t, 42, 50))
+	})
+	t.Run("Should be reproducible from the seed", func(t *testing.T) {
+		require.Equal(t, runShuffled(t, 7, 50), runShuffled(t, 7, 50))
+	})
+	t.Run("Should pick a seed when none is given", func(t *testing.T) {
+		r := actiontest.NewShuffleRunner(t, 0)
+		require.NotZero(t, r.Seed())
+	})
+}
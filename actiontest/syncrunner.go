@@ -0,0 +1,118 @@
+// Package actiontest provides helpers to test code built on top of action.
+package actiontest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neonima/action"
+)
+
+// SyncRunner is an action.Runners executing every action synchronously on
+// the caller's goroutine. Actions are still mutually exclusive, so code
+// relying on the runner's serialization behaves the same in tests.
+//
+// Sending while an action runs, from within it or from another goroutine,
+// queues the action like a real runner would: it runs after the current one
+// returns, on the goroutine running it. Awaiting it from within an action,
+// with Act for instance, deadlocks as it would on a real runner.
+type SyncRunner struct {
+	mu          sync.Mutex
+	running     bool
+	queue       []action.Action
+	isStarted   atomic.Bool
+	ctx         atomic.Pointer[context.Context]
+	middlewares []action.Middleware
+	observers   []action.Observer
+}
+
+var _ action.RunnersV2 = (*SyncRunner)(nil)
+
+// NewSyncRunner returns a SyncRunner usable right away: Start is optional
+// and Ctx defaults to context.Background().
+func NewSyncRunner() *SyncRunner {
+	r := &SyncRunner{}
+	ctx := context.Background()
+	r.ctx.Store(&ctx)
+	return r
+}
+
+// Start sets the runner context. No goroutine is spawned.
+func (r *SyncRunner) Start(ctx context.Context) error {
+	if ctx == nil {
+		return action.ErrNilContext
+	}
+	if !r.isStarted.CompareAndSwap(false, true) {
+		return action.ErrAlreadyStarted
+	}
+	r.ctx.Store(&ctx)
+	return nil
+}
+
+// Send executes the action before returning, along with the actions sent
+// while it runs. If an action is already running, Send queues a behind it
+// and returns.
+func (r *SyncRunner) Send(a action.Action) {
+	r.mu.Lock()
+	r.queue = append(r.queue, a)
+	if r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	drained := false
+	defer func() {
+		// A panicking action must not leave the runner queueing forever.
+		if !drained {
+			r.mu.Lock()
+			r.running = false
+			r.mu.Unlock()
+		}
+	}()
+	for len(r.queue) > 0 {
+		a := r.queue[0]
+		r.queue[0] = nil
+		r.queue = r.queue[1:]
+		middlewares, observers := r.middlewares, r.observers
+		r.mu.Unlock()
+		r.run(a, middlewares, observers)
+		r.mu.Lock()
+	}
+	r.running = false
+	drained = true
+	r.mu.Unlock()
+}
+
+func (r *SyncRunner) run(a action.Action, middlewares []action.Middleware, observers []action.Observer) {
+	if len(observers) > 0 {
+		start := time.Now()
+		defer func() {
+			e := action.ActionEvent{Started: start, Duration: time.Since(start)}
+			for _, o := range observers {
+				o(e)
+			}
+		}()
+	}
+	action.Wrap(a, middlewares...)()
+}
+
+// Use adds a middleware wrapping the next actions.
+func (r *SyncRunner) Use(m action.Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, m)
+}
+
+// Observe adds an observer of the next actions.
+func (r *SyncRunner) Observe(o action.Observer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, o)
+}
+
+// Ctx returns the context
+func (r *SyncRunner) Ctx() context.Context {
+	return *r.ctx.Load()
+}
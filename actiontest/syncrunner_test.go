@@ -0,0 +1,80 @@
+package actiontest_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewSyncRunner(t *testing.T) {
+	t.Run("Should implement Runners", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		require.Implements(t, (*action.Runners)(nil), r)
+		require.NotNil(t, r.Ctx())
+	})
+}
+
+func TestSyncRunner_Send(t *testing.T) {
+	t.Run("Should execute the action before returning", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		called := false
+		r.Send(func() {
+			called = true
+		})
+		require.True(t, called)
+	})
+	t.Run("Should work with the Act helpers without being started", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		v := 0
+		action.Act(r, func() {
+			v++
+		})
+		require.Equal(t, 1, v)
+		require.Equal(t, 1, action.ActGet(r, func() int {
+			return v
+		}))
+	})
+	t.Run("Should run actions told from within an action after it returns", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		var order []string
+		action.Act(r, func() {
+			action.Tell(r, func() {
+				order = append(order, "inner")
+			})
+			order = append(order, "outer")
+		})
+		require.Equal(t, []string{"outer", "inner"}, order)
+	})
+	t.Run("Should keep running actions after one panicked", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		require.Panics(t, func() {
+			r.Send(func() { panic("boom") })
+		})
+		called := false
+		r.Send(func() {
+			called = true
+		})
+		require.True(t, called)
+	})
+}
+
+func TestSyncRunner_Start(t *testing.T) {
+	t.Run("Should use the given context", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		require.NoError(t, r.Start(ctx))
+		require.Equal(t, ctx, r.Ctx())
+	})
+	t.Run("Should fail if started twice", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		require.NoError(t, r.Start(t.Context()))
+		require.ErrorIs(t, r.Start(t.Context()), action.ErrAlreadyStarted)
+	})
+	t.Run("Should fail with a nil context", func(t *testing.T) {
+		r := actiontest.NewSyncRunner()
+		require.ErrorIs(t, r.Start(nil), action.ErrNilContext)
+	})
+}
@@ -0,0 +1,47 @@
+package actiontest
+
+import (
+	"testing"
+	"time"
+)
+
+// StopGracePeriod is how long VerifyStopped waits for a runner to stop once
+// the test is over.
+var StopGracePeriod = time.Second
+
+// Stoppable is implemented by runners reporting when their goroutine exits,
+// such as *action.Runner.
+type Stoppable interface {
+	Done() <-chan struct{}
+}
+
+// VerifyStopped fails the test if any of the given runners is still running
+// at the end of the test. Runners started with t.Context() are stopped by the
+// time the check runs; runners started with a background context are not.
+//
+// Only pass runners that have been started, an idle runner never reports
+// being done.
+func VerifyStopped(t testing.TB, runners ...Stoppable) {
+	t.Helper()
+	t.Cleanup(func() {
+		t.Helper()
+		deadline := time.NewTimer(StopGracePeriod)
+		defer deadline.Stop()
+		expired := false
+		for i, r := range runners {
+			if !expired {
+				select {
+				case <-r.Done():
+					continue
+				case <-deadline.C:
+					expired = true
+				}
+			}
+			select {
+			case <-r.Done():
+			default:
+				t.Errorf("actiontest: runner %d (%T) still running after the test ended", i, r)
+			}
+		}
+	})
+}
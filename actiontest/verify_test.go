@@ -0,0 +1,60 @@
+package actiontest_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type recordingT struct {
+	*testing.T
+	cleanups []func()
+	errors   int
+}
+
+func (r *recordingT) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recordingT) Errorf(string, ...any) {
+	r.errors++
+}
+
+func (r *recordingT) runCleanups() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestVerifyStopped(t *testing.T) {
+	grace := actiontest.StopGracePeriod
+	actiontest.StopGracePeriod = 50 * time.Millisecond
+	t.Cleanup(func() {
+		actiontest.StopGracePeriod = grace
+	})
+
+	t.Run("Should pass when runners are stopped", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		r := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		actiontest.VerifyStopped(rt, r)
+		cancel()
+		rt.runCleanups()
+		require.Zero(t, rt.errors)
+	})
+	t.Run("Should fail for each runner still running", func(t *testing.T) {
+		rt := &recordingT{T: t}
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		r1, r2 := action.New(), action.New()
+		require.NoError(t, r1.Start(ctx))
+		require.NoError(t, r2.Start(ctx))
+		actiontest.VerifyStopped(rt, r1, r2)
+		rt.runCleanups()
+		require.Equal(t, 2, rt.errors)
+	})
+}
@@ -0,0 +1,53 @@
+package actiontest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/neonima/action"
+)
+
+// Watchable is implemented by values publishing their changes, such as
+// *action.RWActable.
+type Watchable[T any] interface {
+	Get() T
+	Watch(ctx context.Context, opts ...func(*action.SubscribeConfig)) <-chan T
+}
+
+var _ Watchable[int] = (*action.RWActable[int])(nil)
+
+// Eventually fails the test unless the value of a equals want, as compared
+// by reflect.DeepEqual, within timeout. It waits for the published values
+// instead of polling, and reports whether want was reached.
+func Eventually[T any](t testing.TB, a Watchable[T], want T, timeout time.Duration) bool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// The latest value matters, older ones must not hold up the runner.
+	c := a.Watch(ctx, action.WithBuffer(1), action.WithOverflow(action.OverflowDropOldest))
+	got := a.Get()
+	for !reflect.DeepEqual(got, want) {
+		v, ok := <-c
+		if !ok {
+			t.Errorf("actiontest: value is %v after %s, want %v", got, timeout, want)
+			return false
+		}
+		got = v
+	}
+	return true
+}
+
+// AwaitChange returns the next value published by a, or the error of ctx if
+// it is done first. Values published before the call are not seen: trigger
+// the change afterwards, or use Eventually.
+func AwaitChange[T any](ctx context.Context, a Watchable[T]) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if v, ok := <-a.Watch(ctx, action.WithBuffer(1), action.WithOverflow(action.OverflowDropOldest)); ok {
+		return v, nil
+	}
+	var zero T
+	return zero, ctx.Err()
+}
@@ -0,0 +1,70 @@
+package actiontest_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestEventually(t *testing.T) {
+	t.Run("Should wait for the value", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		go func() {
+			for i := 1; i <= 100; i++ {
+				a.Set(i)
+			}
+		}()
+		rt := &recordingT{T: t}
+		require.True(t, actiontest.Eventually(rt, a, 100, time.Second))
+		require.Zero(t, rt.errors)
+	})
+	t.Run("Should fail once the timeout passed", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		rt := &recordingT{T: t}
+		require.False(t, actiontest.Eventually(rt, a, 1, 10*time.Millisecond))
+		require.Equal(t, 1, rt.errors)
+	})
+}
+
+func TestAwaitChange(t *testing.T) {
+	t.Run("Should return the next value", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		done, exited := make(chan struct{}), make(chan struct{})
+		defer func() {
+			close(done)
+			<-exited
+		}()
+		go func() {
+			defer close(exited)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					a.Set(1)
+				}
+			}
+		}()
+		v, err := actiontest.AwaitChange(t.Context(), a)
+		require.NoError(t, err)
+		require.Equal(t, 1, v)
+	})
+	t.Run("Should return the context error", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := actiontest.AwaitChange(ctx, a)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
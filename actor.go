@@ -0,0 +1,104 @@
+package action
+
+import (
+	"context"
+	"fmt"
+)
+
+// Behavior handles a message sent to an Actor, with exclusive access to
+// its state.
+type Behavior[S, M any] func(ctx context.Context, state *S, msg M) error
+
+// Actor owns a state of type S and handles messages of type M one at a
+// time, in order, on the runner goroutine. A message sent with Tell whose
+// handling fails stops the actor with the error; Ask returns it instead.
+type Actor[S, M any] struct {
+	runner  *Runner
+	state   S
+	receive Behavior[S, M]
+	failed  error
+}
+
+// NewActor returns an actor in the initial state, handling messages with
+// receive. The options are the ones of New.
+func NewActor[S, M any](initial S, receive Behavior[S, M], opts ...func(*Runner)) *Actor[S, M] {
+	a := &Actor[S, M]{state: initial, receive: receive}
+	a.runner = New(append(opts, WithHook(a.check))...)
+	return a
+}
+
+// check stops the runner with the error of a told message.
+func (a *Actor[S, M]) check(context.Context) error {
+	err := a.failed
+	a.failed = nil
+	return err
+}
+
+// Start starts the runner on a separated goroutine
+func (a *Actor[S, M]) Start(ctx context.Context) error {
+	return a.runner.Start(ctx)
+}
+
+// Tell enqueues the message without waiting for it to be handled.
+func (a *Actor[S, M]) Tell(msg M) {
+	a.runner.Send(func() {
+		a.failed = a.receive(a.runner.Ctx(), &a.state, msg)
+	})
+}
+
+type replyKey struct{}
+
+type reply struct {
+	value any
+	set   bool
+}
+
+// Reply sets the value returned by Ask for the message being handled. It
+// must be called from the Behavior, with the context it received; it does
+// nothing for told messages.
+func Reply(ctx context.Context, v any) {
+	if r, ok := ctx.Value(replyKey{}).(*reply); ok {
+		r.value, r.set = v, true
+	}
+}
+
+// Ask sends the message and waits for it to be handled. It returns the
+// value passed to Reply, or the zero value if there is none, and the error
+// of the Behavior. Like ActGetErrCtx, it stops waiting when ctx or the
+// actor is done.
+func Ask[R, S, M any](ctx context.Context, a *Actor[S, M], msg M) (R, error) {
+	return ActGetErrCtx(ctx, a.runner, func() (R, error) {
+		var zero R
+		rep := &reply{}
+		err := a.receive(context.WithValue(a.runner.Ctx(), replyKey{}, rep), &a.state, msg)
+		if !rep.set {
+			return zero, err
+		}
+		v, ok := rep.value.(R)
+		if !ok && rep.value != nil {
+			return zero, fmt.Errorf("action: reply of type %T, want %T", rep.value, zero)
+		}
+		return v, err
+	})
+}
+
+// Ctx returns the context
+func (a *Actor[S, M]) Ctx() context.Context {
+	return a.runner.Ctx()
+}
+
+// Done returns a channel closed when the runner is stopped.
+func (a *Actor[S, M]) Done() <-chan struct{} {
+	return a.runner.Done()
+}
+
+// Error returns the error of the runner. To be used with Done()
+func (a *Actor[S, M]) Error() error {
+	return a.runner.Error()
+}
+
+// Runner returns the underlying runner, to use the Act helpers alongside
+// messages.
+func (a *Actor[S, M]) Runner() *Runner {
+	return a.runner
+}
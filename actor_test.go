@@ -0,0 +1,59 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type counterMsg struct {
+	add  int
+	fail bool
+}
+
+var errCounter = errors.New("counter")
+
+func counter(ctx context.Context, n *int, msg counterMsg) error {
+	if msg.fail {
+		return errCounter
+	}
+	*n += msg.add
+	action.Reply(ctx, *n)
+	return nil
+}
+
+func TestActor(t *testing.T) {
+	t.Run("Should handle told messages in order", func(t *testing.T) {
+		a := action.NewActor(0, counter, action.WithChanSize(4))
+		require.NoError(t, a.Start(t.Context()))
+		a.Tell(counterMsg{add: 1})
+		a.Tell(counterMsg{add: 2})
+		n, err := action.Ask[int](t.Context(), a, counterMsg{add: 3})
+		require.NoError(t, err)
+		require.Equal(t, 6, n)
+	})
+	t.Run("Should return the error of an asked message", func(t *testing.T) {
+		a := action.NewActor(0, counter)
+		require.NoError(t, a.Start(t.Context()))
+		_, err := action.Ask[int](t.Context(), a, counterMsg{fail: true})
+		require.ErrorIs(t, err, errCounter)
+		n, err := action.Ask[int](t.Context(), a, counterMsg{add: 1})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	})
+	t.Run("Should stop on the error of a told message", func(t *testing.T) {
+		a := action.NewActor(0, counter)
+		require.NoError(t, a.Start(t.Context()))
+		a.Tell(counterMsg{fail: true})
+		<-a.Done()
+		require.ErrorIs(t, a.Error(), errCounter)
+	})
+	t.Run("Should reject a reply of another type", func(t *testing.T) {
+		a := action.NewActor(0, counter)
+		require.NoError(t, a.Start(t.Context()))
+		_, err := action.Ask[string](t.Context(), a, counterMsg{add: 1})
+		require.Error(t, err)
+	})
+}
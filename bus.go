@@ -0,0 +1,173 @@
+package action
+
+import (
+	"context"
+	"sync"
+)
+
+// Overflow decides what a Bus does when a subscriber's buffer is full.
+type Overflow int
+
+const (
+	// OverflowBlock waits for the subscriber to receive, holding up the bus.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest discards the event being published.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest
+)
+
+// SubscribeConfig holds a Bus subscriber settings.
+type SubscribeConfig struct {
+	Buffer   int
+	Overflow Overflow
+}
+
+// WithBuffer sets the subscriber channel capacity, default is 16. With 0 and
+// a drop policy, events are dropped unless the subscriber is receiving.
+func WithBuffer(size int) func(*SubscribeConfig) {
+	return func(c *SubscribeConfig) {
+		if size < 0 {
+			return
+		}
+		c.Buffer = size
+	}
+}
+
+// WithOverflow sets the subscriber overflow policy, default is OverflowBlock.
+func WithOverflow(o Overflow) func(*SubscribeConfig) {
+	return func(c *SubscribeConfig) {
+		c.Overflow = o
+	}
+}
+
+type subscriber[T any] struct {
+	ctx      context.Context
+	c        chan T
+	overflow Overflow
+	// mu guards c against being closed while an event is delivered.
+	mu     sync.Mutex
+	closed bool
+}
+
+// Bus fans out published events to every subscriber. Publishing and
+// subscription changes are serialized by a runner, so subscribers see events
+// in publish order.
+type Bus[T any] struct {
+	r    Runners
+	subs map[*subscriber[T]]struct{}
+}
+
+// NewBus returns a Bus publishing through r.
+func NewBus[T any](r Runners) *Bus[T] {
+	return &Bus[T]{
+		r:    r,
+		subs: make(map[*subscriber[T]]struct{}),
+	}
+}
+
+// Publish delivers the event to the current subscribers according to their
+// overflow policy, and returns once it has been handed to all of them.
+func (b *Bus[T]) Publish(event T) {
+	Act(b.r, func() {
+		for s := range b.subs {
+			if !s.deliver(event) {
+				delete(b.subs, s)
+			}
+		}
+	})
+}
+
+// deliver hands the event to the subscriber. It reports false once the
+// subscriber is gone.
+func (s *subscriber[T]) deliver(event T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	switch s.overflow {
+	case OverflowDropNewest:
+		select {
+		case s.c <- event:
+		default:
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.c <- event:
+				return true
+			default:
+			}
+			// Unbuffered, there is no oldest event to make room from.
+			if cap(s.c) == 0 {
+				return true
+			}
+			select {
+			case <-s.c:
+			default:
+			}
+		}
+	default:
+		select {
+		case s.c <- event:
+		case <-s.ctx.Done():
+		}
+	}
+	return true
+}
+
+// newSubscriber returns a subscriber configured by opts. It reports false,
+// with the channel closed, if ctx is already done.
+func newSubscriber[T any](ctx context.Context, opts []func(*SubscribeConfig)) (*subscriber[T], bool) {
+	cfg := SubscribeConfig{Buffer: 16}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &subscriber[T]{
+		ctx:      ctx,
+		c:        make(chan T, cfg.Buffer),
+		overflow: cfg.Overflow,
+	}
+	if ctx.Err() != nil {
+		s.closed = true
+		close(s.c)
+		return s, false
+	}
+	return s, true
+}
+
+func (s *subscriber[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.c)
+}
+
+// Subscribe returns a channel receiving the events published from now on.
+// The channel is closed once ctx is done.
+func (b *Bus[T]) Subscribe(ctx context.Context, opts ...func(*SubscribeConfig)) <-chan T {
+	s, ok := newSubscriber[T](ctx, opts)
+	if !ok {
+		return s.c
+	}
+	Act(b.r, func() {
+		b.subs[s] = struct{}{}
+	})
+	// Closed outside of the runner, which may be stopped by then. The bus
+	// forgets the subscriber on the next Publish.
+	context.AfterFunc(ctx, s.close)
+	return s.c
+}
+
+// Len returns the number of subscribers.
+func (b *Bus[T]) Len() int {
+	return ActGet(b.r, func() int {
+		for s := range b.subs {
+			if s.ctx.Err() != nil {
+				delete(b.subs, s)
+			}
+		}
+		return len(b.subs)
+	})
+}
@@ -0,0 +1,88 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("Should deliver events to every subscriber in order", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		b := action.NewBus[int](r)
+		s1 := b.Subscribe(t.Context())
+		s2 := b.Subscribe(t.Context())
+		b.Publish(1)
+		b.Publish(2)
+		require.Equal(t, 1, <-s1)
+		require.Equal(t, 2, <-s1)
+		require.Equal(t, 1, <-s2)
+		require.Equal(t, 2, <-s2)
+	})
+	t.Run("Should close the channel once the subscriber context is done", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		b := action.NewBus[int](r)
+		ctx, cancel := context.WithCancel(t.Context())
+		s := b.Subscribe(ctx)
+		require.Equal(t, 1, b.Len())
+		cancel()
+		_, ok := <-s
+		require.False(t, ok)
+		require.Equal(t, 0, b.Len())
+	})
+	t.Run("Should not block on a dead subscriber", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		b := action.NewBus[int](r)
+		ctx, cancel := context.WithCancel(t.Context())
+		b.Subscribe(ctx, action.WithBuffer(0))
+		cancel()
+		b.Publish(1)
+	})
+}
+
+func TestBus_Overflow(t *testing.T) {
+	tt := []struct {
+		title    string
+		overflow action.Overflow
+		expected []int
+	}{
+		{
+			title:    "Should drop the newest events",
+			overflow: action.OverflowDropNewest,
+			expected: []int{1, 2},
+		},
+		{
+			title:    "Should drop the oldest events",
+			overflow: action.OverflowDropOldest,
+			expected: []int{3, 4},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.title, func(t *testing.T) {
+			r := action.New()
+			require.NoError(t, r.Start(t.Context()))
+			b := action.NewBus[int](r)
+			s := b.Subscribe(t.Context(), action.WithBuffer(2), action.WithOverflow(tc.overflow))
+			for i := 1; i <= 4; i++ {
+				b.Publish(i)
+			}
+			require.Equal(t, tc.expected, []int{<-s, <-s})
+		})
+	}
+	t.Run("Should not block dropping the oldest events without buffer", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		b := action.NewBus[int](r)
+		ctx, cancel := context.WithCancel(t.Context())
+		s := b.Subscribe(ctx, action.WithBuffer(0), action.WithOverflow(action.OverflowDropOldest))
+		b.Publish(1)
+		cancel()
+		_, ok := <-s
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,73 @@
+package action
+
+import (
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration read from and written to text as "1.5s", for
+// use in configuration files and environment variables.
+type Duration time.Duration
+
+// UnmarshalText parses a duration as accepted by time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration like time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Config describes a runner with plain values, so it can be loaded from
+// JSON, YAML or the environment. Zero values keep the defaults of New.
+type Config struct {
+	// Name, see WithName.
+	Name string `json:"name" yaml:"name"`
+	// ChanSize is the queue capacity, see WithChanSize.
+	ChanSize int `json:"chan_size" yaml:"chan_size"`
+	// ActionTimeout, see WithActionTimeout.
+	ActionTimeout Duration `json:"action_timeout" yaml:"action_timeout"`
+	// IdleTimeout, see WithIdleTimeout.
+	IdleTimeout Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	// InlineExecution, see WithInlineExecution.
+	InlineExecution bool `json:"inline_execution" yaml:"inline_execution"`
+	// HookBatchSize and HookBatchInterval, see WithHookBatch.
+	HookBatchSize     int      `json:"hook_batch_size" yaml:"hook_batch_size"`
+	HookBatchInterval Duration `json:"hook_batch_interval" yaml:"hook_batch_interval"`
+}
+
+// Options returns the options matching the configuration.
+func (c Config) Options() []func(*Runner) {
+	var opts []func(*Runner)
+	if c.Name != "" {
+		opts = append(opts, WithName(c.Name))
+	}
+	if c.ChanSize != 0 {
+		opts = append(opts, WithChanSize(c.ChanSize))
+	}
+	if c.ActionTimeout != 0 {
+		opts = append(opts, WithActionTimeout(time.Duration(c.ActionTimeout)))
+	}
+	if c.IdleTimeout != 0 {
+		opts = append(opts, WithIdleTimeout(time.Duration(c.IdleTimeout)))
+	}
+	if c.InlineExecution {
+		opts = append(opts, WithInlineExecution())
+	}
+	if c.HookBatchSize != 0 || c.HookBatchInterval != 0 {
+		opts = append(opts, WithHookBatch(c.HookBatchSize, time.Duration(c.HookBatchInterval)))
+	}
+	return opts
+}
+
+// NewFromConfig returns a runner configured by c, then by opts, validated
+// like NewChecked. Code-only settings such as hooks are passed as opts.
+func NewFromConfig(c Config, opts ...func(*Runner)) (*Runner, error) {
+	return NewChecked(append(c.Options(), opts...)...)
+}
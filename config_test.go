@@ -0,0 +1,72 @@
+package action_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+func TestConfig_Unmarshal(t *testing.T) {
+	expected := action.Config{
+		Name:          "config",
+		ChanSize:      10,
+		ActionTimeout: action.Duration(1500 * time.Millisecond),
+	}
+	t.Run("Should load from JSON", func(t *testing.T) {
+		var c action.Config
+		require.NoError(t, json.Unmarshal([]byte(`{"name":"config","chan_size":10,"action_timeout":"1.5s"}`), &c))
+		require.Equal(t, expected, c)
+	})
+	t.Run("Should load from YAML", func(t *testing.T) {
+		var c action.Config
+		require.NoError(t, yaml.Unmarshal([]byte("name: config\nchan_size: 10\naction_timeout: 1.5s\n"), &c))
+		require.Equal(t, expected, c)
+	})
+	t.Run("Should reject invalid durations", func(t *testing.T) {
+		var c action.Config
+		require.ErrorIs(t, json.Unmarshal([]byte(`{"action_timeout":"soon"}`), &c), action.ErrInvalidOption)
+	})
+}
+
+func TestNewFromConfig(t *testing.T) {
+	t.Run("Should apply the configuration", func(t *testing.T) {
+		r, err := action.NewFromConfig(action.Config{
+			Name:          "from-config",
+			ActionTimeout: action.Duration(10 * time.Millisecond),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "from-config", r.Name())
+		_, ok := action.Lookup("from-config")
+		require.False(t, ok)
+		require.NoError(t, r.Start(t.Context()))
+		t.Cleanup(func() { action.Deregister("from-config") })
+		got, ok := action.Lookup("from-config")
+		require.True(t, ok)
+		require.Equal(t, r, got)
+		require.ErrorIs(t, action.ActWithContext(t.Context(), r, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), context.DeadlineExceeded)
+	})
+	t.Run("Should apply options after the configuration", func(t *testing.T) {
+		called := false
+		r, err := action.NewFromConfig(action.Config{}, action.WithHook(func(ctx context.Context) error {
+			called = true
+			return nil
+		}))
+		require.NoError(t, err)
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() {})
+		require.True(t, action.ActGet(r, func() bool {
+			return called
+		}))
+	})
+	t.Run("Should reject invalid values", func(t *testing.T) {
+		_, err := action.NewFromConfig(action.Config{ChanSize: -1})
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
@@ -0,0 +1,79 @@
+package action
+
+import (
+	"errors"
+	"time"
+)
+
+// DeadLetter is an action that was dropped instead of executed.
+type DeadLetter struct {
+	// Action is the dropped action. Calling it runs it.
+	Action Action
+	// Enqueued is when the action was sent.
+	Enqueued time.Time
+	// Reason is why the action was dropped: the runner error for actions
+	// still queued when it stopped, or the caller context error for actions
+	// of ActWithContext and ActGetErrCtx skipped because the caller gave up.
+	Reason error
+}
+
+// WithDeadLetter makes the runner report the actions it drops to f, on the
+// runner goroutine. Actions still queued when the runner stops are reported
+// instead of being yielded by Remaining, unless the runner was handed off.
+func WithDeadLetter(f func(DeadLetter)) func(*Runner) {
+	return func(r *Runner) {
+		if f == nil {
+			r.invalid("nil dead letter func")
+			return
+		}
+		r.deadLetter = f
+	}
+}
+
+// letter wraps a with its send time, reporting it as dropped instead of
+// running it once the runner is dropping its queue.
+func (r *Runner) letter(a Action) Action {
+	enqueued := time.Now()
+	return func() {
+		if r.dropping != nil {
+			r.deadLetter(DeadLetter{Action: a, Enqueued: enqueued, Reason: r.dropping})
+			return
+		}
+		a()
+	}
+}
+
+// dropQueued reports the actions left in the queues. It must be called on
+// the runner goroutine once they are closed.
+func (r *Runner) dropQueued() {
+	if r.deadLetter == nil || r.handedOff {
+		return
+	}
+	r.dropping = r.Error()
+	if r.dropping == nil {
+		r.dropping = ErrClosed
+	}
+	for a := range r.queuedActions() {
+		a()
+	}
+}
+
+// dropped reports an action skipped by its caller.
+func (r *Runner) dropped(a Action, enqueued time.Time, reason error) {
+	if r.telemetry != nil && errors.Is(reason, ErrTimeout) {
+		r.telemetry.Count(MetricTimeout, 1)
+	}
+	if r.deadLetter != nil {
+		r.deadLetter(DeadLetter{Action: a, Enqueued: enqueued, Reason: reason})
+	}
+}
+
+// reportDropped reports an action skipped by its caller if r supports dead
+// letters.
+func reportDropped(r Runners, a Action, enqueued time.Time, reason error) {
+	if d, ok := r.(interface {
+		dropped(Action, time.Time, error)
+	}); ok {
+		d.dropped(a, enqueued, reason)
+	}
+}
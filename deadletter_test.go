@@ -0,0 +1,63 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithDeadLetter(t *testing.T) {
+	t.Run("Should report the actions queued when the runner stops", func(t *testing.T) {
+		var letters []action.DeadLetter
+		errStop := errors.New("stop")
+		stop := false
+		r := action.New(action.WithChanSize(4), action.WithDeadLetter(func(l action.DeadLetter) {
+			letters = append(letters, l)
+		}), action.WithHook(func(context.Context) error {
+			if stop {
+				return errStop
+			}
+			return nil
+		}))
+		before := time.Now()
+		ran := 0
+		r.Send(func() { stop = true })
+		r.Send(func() { ran++ })
+		r.Send(func() { ran++ })
+		require.NoError(t, r.Start(t.Context()))
+		<-r.Done()
+		require.Len(t, letters, 2)
+		require.ErrorIs(t, letters[0].Reason, errStop)
+		require.False(t, letters[0].Enqueued.Before(before))
+		letters[0].Action()
+		require.Equal(t, 1, ran)
+		require.Empty(t, slices.Collect(r.Remaining()))
+	})
+	t.Run("Should report the actions skipped by their caller", func(t *testing.T) {
+		letters := make(chan action.DeadLetter, 1)
+		r := action.New(action.WithChanSize(2), action.WithDeadLetter(func(l action.DeadLetter) {
+			letters <- l
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		err := action.ActWithContext(ctx, r, func(context.Context) error { return nil })
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		close(release)
+		require.ErrorIs(t, (<-letters).Reason, context.DeadlineExceeded)
+	})
+	t.Run("Should reject a nil func", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithDeadLetter(nil))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
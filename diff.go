@@ -0,0 +1,73 @@
+package action
+
+import (
+	"context"
+	"reflect"
+)
+
+// FieldChange is a field whose value differs between two struct values, see
+// Diff.
+type FieldChange struct {
+	// Field is the field name, empty when the values are not structs.
+	Field string
+	Old   any
+	New   any
+}
+
+// Diff returns the exported fields of old and new that differ, compared with
+// reflect.DeepEqual, in declaration order. Pointers and interfaces holding
+// structs are followed, and nested structs are compared as a whole. Values
+// of other types, or nil pointers, are reported as a single change without a
+// field name if they differ.
+func Diff[T any](old, new T) []FieldChange {
+	ov, nv := reflect.ValueOf(&old).Elem(), reflect.ValueOf(&new).Elem()
+	for (ov.Kind() == reflect.Pointer || ov.Kind() == reflect.Interface) && !ov.IsNil() && !nv.IsNil() {
+		ov, nv = ov.Elem(), nv.Elem()
+	}
+	if ov.Kind() != reflect.Struct || ov.Type() != nv.Type() {
+		if reflect.DeepEqual(old, new) {
+			return nil
+		}
+		return []FieldChange{{Old: old, New: new}}
+	}
+	var changes []FieldChange
+	for i := range ov.NumField() {
+		f := ov.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		o, n := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if !reflect.DeepEqual(o, n) {
+			changes = append(changes, FieldChange{Field: f.Name, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+// OnDiff calls f with the fields changed by every write, see Diff and
+// OnChange. Writes changing no field are skipped. f is called on the runner,
+// so it must not wait on the runner.
+func (a *RWActable[T]) OnDiff(f func([]FieldChange)) (cancel func()) {
+	return a.OnChange(func(old, new T) {
+		if changes := Diff(old, new); len(changes) > 0 {
+			f(changes)
+		}
+	})
+}
+
+// WatchDiff is like Watch, but the channel receives the fields changed by
+// each write, see Diff. Writes changing no field are skipped.
+func (a *RWActable[T]) WatchDiff(ctx context.Context, opts ...func(*SubscribeConfig)) <-chan []FieldChange {
+	s, ok := newSubscriber[[]FieldChange](ctx, opts)
+	if !ok {
+		return s.c
+	}
+	cancel := a.OnDiff(func(changes []FieldChange) {
+		s.deliver(changes)
+	})
+	context.AfterFunc(ctx, func() {
+		cancel()
+		s.close()
+	})
+	return s.c
+}
@@ -0,0 +1,89 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type account struct {
+	Owner   string
+	Balance int
+	Tags    []string
+	secret  string
+}
+
+func TestDiff(t *testing.T) {
+	tt := []struct {
+		title    string
+		old, new any
+		expected []action.FieldChange
+	}{
+		{
+			title:    "Should report the changed fields in order",
+			old:      account{Owner: "ann", Balance: 1, Tags: []string{"a"}},
+			new:      account{Owner: "bob", Balance: 1, Tags: []string{"b"}},
+			expected: []action.FieldChange{{Field: "Owner", Old: "ann", New: "bob"}, {Field: "Tags", Old: []string{"a"}, New: []string{"b"}}},
+		},
+		{
+			title: "Should ignore unexported fields",
+			old:   account{secret: "a"},
+			new:   account{secret: "b"},
+		},
+		{
+			title:    "Should follow pointers",
+			old:      &account{Balance: 1},
+			new:      &account{Balance: 2},
+			expected: []action.FieldChange{{Field: "Balance", Old: 1, New: 2}},
+		},
+		{
+			title:    "Should report other values as a whole",
+			old:      1,
+			new:      2,
+			expected: []action.FieldChange{{Old: 1, New: 2}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.title, func(t *testing.T) {
+			require.Equal(t, tc.expected, action.Diff(tc.old, tc.new))
+		})
+	}
+}
+
+func TestRWActable_OnDiff(t *testing.T) {
+	t.Run("Should report the fields changed by each write", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, account{Owner: "ann"})
+		var diffs [][]action.FieldChange
+		a.OnDiff(func(changes []action.FieldChange) {
+			diffs = append(diffs, changes)
+		})
+		a.Set(account{Owner: "ann"})
+		a.Update(func(v account) account {
+			v.Balance = 10
+			return v
+		})
+		require.Equal(t, [][]action.FieldChange{{{Field: "Balance", Old: 0, New: 10}}},
+			action.ActGet(r, func() [][]action.FieldChange { return diffs }))
+	})
+}
+
+func TestRWActable_WatchDiff(t *testing.T) {
+	t.Run("Should receive the fields changed by each write", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, account{})
+		c := a.WatchDiff(t.Context())
+		a.Set(account{})
+		a.Set(account{Owner: "ann"})
+		select {
+		case changes := <-c:
+			require.Equal(t, []action.FieldChange{{Field: "Owner", Old: "", New: "ann"}}, changes)
+		case <-time.After(time.Second):
+			t.Fatal("no diff received")
+		}
+	})
+}
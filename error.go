@@ -1,8 +1,41 @@
 package action
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrAlreadyStarted = errors.New("runner already started")
 	ErrNilContext     = errors.New("context is nil")
+	ErrNameTaken      = errors.New("name already registered")
+	ErrInvalidOption  = errors.New("invalid option")
+	ErrClosed         = errors.New("runner closed")
+	ErrNotStarted     = errors.New("runner not started")
+	ErrNotStopped     = errors.New("runner not stopped")
+	ErrQuotaExceeded  = errors.New("quota exceeded")
+	ErrRestartLimit   = errors.New("restart limit exceeded")
+	ErrReleased       = errors.New("resource released")
+	// ErrTimeout is returned by the Act helpers when the caller's context
+	// deadline passed before the action returned.
+	ErrTimeout = errors.New("action timed out")
+	// ErrStopped is returned by the Act helpers when the runner stopped
+	// before the action returned. It wraps the runner context cause.
+	ErrStopped = errors.New("runner stopped")
 )
+
+// stopped returns the error of a call whose runner, of context ctx, stopped.
+func stopped(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrStopped, context.Cause(ctx))
+}
+
+// abandoned returns the error of a call whose caller's ctx is done,
+// wrapping ErrTimeout if its deadline passed.
+func abandoned(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
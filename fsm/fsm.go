@@ -0,0 +1,108 @@
+// Package fsm provides a finite state machine whose events are delivered one
+// at a time through an action runner.
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neonima/action"
+)
+
+var (
+	ErrIllegalTransition = errors.New("fsm: illegal transition")
+	ErrGuardRejected     = errors.New("fsm: transition rejected by guard")
+)
+
+// Transition moves the machine from From to To when Event is fired.
+type Transition[S, E comparable] struct {
+	From  S
+	Event E
+	To    S
+	// Guard, if set, must return true for the transition to happen.
+	Guard func(ctx context.Context) bool
+	// Action, if set, runs before the state changes. An error aborts the
+	// transition and is returned by Fire.
+	Action func(ctx context.Context) error
+}
+
+type key[S, E comparable] struct {
+	from  S
+	event E
+}
+
+// Machine is a finite state machine. All its methods are safe for concurrent
+// use: events, guards, actions and hooks run on the runner.
+type Machine[S, E comparable] struct {
+	r           action.Runners
+	state       S
+	transitions map[key[S, E]]Transition[S, E]
+	hooks       []func(from, to S, event E)
+}
+
+// New returns a machine in the initial state, delivering events through r,
+// which must be started to fire events. Declaring the same From and Event
+// twice keeps the last transition.
+func New[S, E comparable](r action.Runners, initial S, transitions ...Transition[S, E]) *Machine[S, E] {
+	m := &Machine[S, E]{
+		r:           r,
+		state:       initial,
+		transitions: make(map[key[S, E]]Transition[S, E], len(transitions)),
+	}
+	for _, t := range transitions {
+		m.transitions[key[S, E]{t.From, t.Event}] = t
+	}
+	return m
+}
+
+// Fire delivers the event and returns once the transition is done.
+// It returns an error wrapping ErrIllegalTransition if no transition is
+// declared for the event in the current state, ErrGuardRejected if the guard
+// refused it, or the error of the transition action.
+func (m *Machine[S, E]) Fire(event E) error {
+	return action.ActErr(m.r, func() error {
+		ctx := m.r.Ctx()
+		from := m.state
+		t, ok := m.transitions[key[S, E]{from, event}]
+		if !ok {
+			return fmt.Errorf("%w: %v in state %v", ErrIllegalTransition, event, from)
+		}
+		if t.Guard != nil && !t.Guard(ctx) {
+			return fmt.Errorf("%w: %v in state %v", ErrGuardRejected, event, from)
+		}
+		if t.Action != nil {
+			if err := t.Action(ctx); err != nil {
+				return err
+			}
+		}
+		m.state = t.To
+		for _, h := range m.hooks {
+			h(from, t.To, event)
+		}
+		return nil
+	})
+}
+
+// State returns the current state.
+func (m *Machine[S, E]) State() S {
+	return action.ActGet(m.r, func() S {
+		return m.state
+	})
+}
+
+// Can reports whether the event would match a transition in the current
+// state, without evaluating its guard.
+func (m *Machine[S, E]) Can(event E) bool {
+	return action.ActGet(m.r, func() bool {
+		_, ok := m.transitions[key[S, E]{m.state, event}]
+		return ok
+	})
+}
+
+// OnTransition registers a hook called on the runner after each transition.
+func (m *Machine[S, E]) OnTransition(h func(from, to S, event E)) {
+	action.Act(m.r, func() {
+		m.hooks = append(m.hooks, h)
+	})
+}
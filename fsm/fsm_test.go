@@ -0,0 +1,116 @@
+package fsm_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/neonima/action/fsm"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type state string
+type event string
+
+const (
+	closed state = "closed"
+	opened state = "opened"
+	locked state = "locked"
+
+	open   event = "open"
+	close_ event = "close"
+	lock   event = "lock"
+)
+
+func newDoor(t *testing.T, canLock bool) *fsm.Machine[state, event] {
+	r := action.New()
+	require.NoError(t, r.Start(t.Context()))
+	return fsm.New(r, closed,
+		fsm.Transition[state, event]{From: closed, Event: open, To: opened},
+		fsm.Transition[state, event]{From: opened, Event: close_, To: closed},
+		fsm.Transition[state, event]{From: closed, Event: lock, To: locked, Guard: func(context.Context) bool {
+			return canLock
+		}},
+	)
+}
+
+func TestMachine_Fire(t *testing.T) {
+	tt := []struct {
+		title         string
+		canLock       bool
+		events        []event
+		expectedState state
+		expectedErr   error
+	}{
+		{
+			title:         "Should follow declared transitions",
+			canLock:       true,
+			events:        []event{open, close_, lock},
+			expectedState: locked,
+		},
+		{
+			title:         "Should reject illegal transitions",
+			canLock:       true,
+			events:        []event{close_},
+			expectedState: closed,
+			expectedErr:   fsm.ErrIllegalTransition,
+		},
+		{
+			title:         "Should reject transitions refused by the guard",
+			canLock:       false,
+			events:        []event{lock},
+			expectedState: closed,
+			expectedErr:   fsm.ErrGuardRejected,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.title, func(t *testing.T) {
+			m := newDoor(t, tc.canLock)
+			var err error
+			for _, e := range tc.events {
+				err = m.Fire(e)
+			}
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedState, m.State())
+		})
+	}
+}
+
+func TestMachine_Action(t *testing.T) {
+	t.Run("Should abort the transition if the action fails", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		boom := errors.New("boom")
+		m := fsm.New(r, closed, fsm.Transition[state, event]{
+			From:   closed,
+			Event:  open,
+			To:     opened,
+			Action: func(context.Context) error { return boom },
+		})
+		require.ErrorIs(t, m.Fire(open), boom)
+		require.Equal(t, closed, m.State())
+	})
+}
+
+func TestMachine_OnTransition(t *testing.T) {
+	t.Run("Should call hooks after each transition", func(t *testing.T) {
+		m := newDoor(t, true)
+		var got []string
+		m.OnTransition(func(from, to state, e event) {
+			got = append(got, string(from)+"-"+string(e)+"->"+string(to))
+		})
+		require.NoError(t, m.Fire(open))
+		require.Error(t, m.Fire(lock))
+		require.NoError(t, m.Fire(close_))
+		require.Equal(t, []string{"closed-open->opened", "opened-close->closed"}, got)
+	})
+}
+
+func TestMachine_Can(t *testing.T) {
+	t.Run("Should report transitions available in the current state", func(t *testing.T) {
+		m := newDoor(t, true)
+		require.True(t, m.Can(open))
+		require.False(t, m.Can(close_))
+	})
+}
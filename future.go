@@ -0,0 +1,92 @@
+package action
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the pending result of an action enqueued with ActAsync.
+type Future[T any] struct {
+	ctx    context.Context
+	done   chan struct{}
+	mu     sync.Mutex
+	result Result[T]
+	thens  []func(T)
+}
+
+// ActAsync enqueues the action without waiting for it and returns a future
+// of its result.
+func ActAsync[T any](r Runners, action ActionReturnWithError[T]) *Future[T] {
+	f := &Future[T]{ctx: r.Ctx(), done: make(chan struct{})}
+	r.Send(func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) {
+				select {
+				case <-f.done:
+					// A Then callback panicked.
+				default:
+					f.complete(Err[T](pe))
+				}
+			})()
+		}
+		f.complete(ResultOf(action()))
+	})
+	return f
+}
+
+func (f *Future[T]) complete(res Result[T]) {
+	f.mu.Lock()
+	f.result = res
+	thens := f.thens
+	f.thens = nil
+	close(f.done)
+	f.mu.Unlock()
+	if v, err := res.Unwrap(); err == nil {
+		for _, then := range thens {
+			then(v)
+		}
+	}
+}
+
+// Wait blocks until the action has run and returns its result. It returns
+// early once ctx or the runner is done, like ActGetErrCtx.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	var zero T
+	select {
+	case <-f.done:
+		return f.result.Unwrap()
+	case <-ctx.Done():
+		return zero, abandoned(ctx)
+	case <-f.ctx.Done():
+		select {
+		case <-f.done:
+			return f.result.Unwrap()
+		default:
+			return zero, stopped(f.ctx)
+		}
+	}
+}
+
+// Done returns a channel closed once the action has run.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Then registers a callback receiving the value of a successful action. It
+// runs on the runner goroutine right after the action, or immediately on the
+// caller's goroutine if the action has already run. It returns f for
+// chaining.
+func (f *Future[T]) Then(then func(T)) *Future[T] {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		if v, err := f.result.Unwrap(); err == nil {
+			then(v)
+		}
+	default:
+		f.thens = append(f.thens, then)
+		f.mu.Unlock()
+	}
+	return f
+}
@@ -0,0 +1,70 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestActAsync(t *testing.T) {
+	t.Run("Should return the result without blocking the caller", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		release := make(chan struct{})
+		f := action.ActAsync(r, func() (int, error) {
+			<-release
+			return 42, nil
+		})
+		select {
+		case <-f.Done():
+			t.Fatal("future done before the action ran")
+		default:
+		}
+		close(release)
+		v, err := f.Wait(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 42, v)
+	})
+	t.Run("Should return the action error", func(t *testing.T) {
+		errAction := errors.New("action")
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		f := action.ActAsync(r, func() (int, error) {
+			return 0, errAction
+		})
+		_, err := f.Wait(t.Context())
+		require.ErrorIs(t, err, errAction)
+	})
+	t.Run("Should stop waiting when the context is done", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		release := make(chan struct{})
+		defer close(release)
+		f := action.ActAsync(r, func() (int, error) {
+			<-release
+			return 42, nil
+		})
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := f.Wait(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("Should call Then with the value", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		release := make(chan struct{})
+		f := action.ActAsync(r, func() (int, error) {
+			<-release
+			return 21, nil
+		})
+		got := make(chan int, 2)
+		f.Then(func(v int) { got <- v * 2 })
+		close(release)
+		require.Equal(t, 42, <-got)
+		f.Then(func(v int) { got <- v })
+		require.Equal(t, 21, <-got)
+	})
+}
@@ -0,0 +1,132 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Group starts runners depending on each other in dependency order, and
+// closes them in reverse order, like services wired together.
+type Group struct {
+	mu      sync.Mutex
+	members []*member
+	started []Runners
+}
+
+type member struct {
+	r     Runners
+	after []Runners
+}
+
+// GroupOption configures a runner added to a Group.
+type GroupOption func(*member)
+
+// After makes the runner start after deps, and close before them. The
+// dependencies must be added to the same group.
+func After(deps ...Runners) GroupOption {
+	return func(m *member) {
+		m.after = append(m.after, deps...)
+	}
+}
+
+// NewGroup returns an empty Group.
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Add adds r to the group.
+func (g *Group) Add(r Runners, opts ...GroupOption) {
+	m := &member{r: r}
+	for _, opt := range opts {
+		opt(m)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.members = append(g.members, m)
+}
+
+// Start starts the runners, each after its dependencies, in the order they
+// were added otherwise. If a runner fails to start, or the dependencies
+// cannot be ordered, the runners already started are closed and the error
+// is returned.
+func (g *Group) Start(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order, err := g.order()
+	if err != nil {
+		return err
+	}
+	for _, r := range order {
+		if err := r.Start(ctx); err != nil {
+			return errors.Join(err, g.closeStarted())
+		}
+		g.started = append(g.started, r)
+	}
+	return nil
+}
+
+// Close closes the started runners implementing io.Closer, such as *Runner,
+// in the reverse order of their start.
+func (g *Group) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.closeStarted()
+}
+
+func (g *Group) closeStarted() error {
+	var errs []error
+	for _, r := range slices.Backward(g.started) {
+		if c, ok := r.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	g.started = nil
+	return errors.Join(errs...)
+}
+
+// order sorts the members topologically, keeping the insertion order
+// between independent runners.
+func (g *Group) order() ([]Runners, error) {
+	index := make(map[Runners]int, len(g.members))
+	for i, m := range g.members {
+		index[m.r] = i
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.members))
+	order := make([]Runners, 0, len(g.members))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.New("action: dependency cycle in group")
+		}
+		state[i] = visiting
+		for _, dep := range g.members[i].after {
+			j, ok := index[dep]
+			if !ok {
+				return errors.New("action: dependency outside of the group")
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, g.members[i].r)
+		return nil
+	}
+	for i := range g.members {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,60 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type namedRunner struct {
+	*action.Runner
+	name string
+	log  *[]string
+}
+
+func (r namedRunner) Start(ctx context.Context) error {
+	*r.log = append(*r.log, "start "+r.name)
+	return r.Runner.Start(ctx)
+}
+
+func (r namedRunner) Close() error {
+	*r.log = append(*r.log, "close "+r.name)
+	return r.Runner.Close()
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("Should start in dependency order and close in reverse", func(t *testing.T) {
+		var log []string
+		db := namedRunner{action.New(), "db", &log}
+		cache := namedRunner{action.New(), "cache", &log}
+		api := namedRunner{action.New(), "api", &log}
+		g := action.NewGroup()
+		g.Add(api, action.After(cache, db))
+		g.Add(cache, action.After(db))
+		g.Add(db)
+		require.NoError(t, g.Start(t.Context()))
+		require.NoError(t, g.Close())
+		require.Equal(t, []string{
+			"start db", "start cache", "start api",
+			"close api", "close cache", "close db",
+		}, log)
+	})
+	t.Run("Should reject cycles", func(t *testing.T) {
+		a, b := action.New(), action.New()
+		g := action.NewGroup()
+		g.Add(a, action.After(b))
+		g.Add(b, action.After(a))
+		require.Error(t, g.Start(t.Context()))
+	})
+	t.Run("Should close the started runners when one fails", func(t *testing.T) {
+		a, b := action.New(), action.New()
+		require.NoError(t, b.Start(t.Context()))
+		g := action.NewGroup()
+		g.Add(a)
+		g.Add(b, action.After(a))
+		require.ErrorIs(t, g.Start(t.Context()), action.ErrAlreadyStarted)
+		<-a.Done()
+		require.ErrorIs(t, a.Error(), action.ErrClosed)
+	})
+}
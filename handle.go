@@ -0,0 +1,75 @@
+package action
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+const (
+	handlePending int32 = iota
+	handleRunning
+	handleCancelled
+)
+
+// Handle tracks an action enqueued with Submit.
+type Handle struct {
+	state atomic.Int32
+	done  chan struct{}
+	// unwatch releases the watch of the runner stop, see watch.
+	unwatch atomic.Pointer[func() bool]
+}
+
+// Submit enqueues the action without waiting for it and returns a handle to
+// cancel it before it starts or to observe its completion. If r is a
+// *Runner stopping before the action starts, the action is withdrawn like
+// with Cancel, unless its WithDeadLetter sink runs it.
+func Submit(r Runners, action Action) *Handle {
+	h := &Handle{done: make(chan struct{})}
+	if rr, ok := r.(*Runner); ok {
+		h.watch(rr)
+	}
+	r.Send(func() {
+		if !h.state.CompareAndSwap(handlePending, handleRunning) {
+			return
+		}
+		if unwatch := h.unwatch.Load(); unwatch != nil {
+			(*unwatch)()
+		}
+		defer close(h.done)
+		action()
+	})
+	return h
+}
+
+// watch withdraws the action once r stops, following its handoff.
+func (h *Handle) watch(r *Runner) {
+	unwatch := context.AfterFunc(r.DoneCtx(), func() {
+		if to := r.redirect.Load(); to != nil {
+			h.watch(to)
+			return
+		}
+		h.Cancel()
+	})
+	h.unwatch.Store(&unwatch)
+}
+
+// Cancel withdraws the action if it has not started yet. It reports whether
+// the action was withdrawn; if so, Done is closed and the action never runs.
+func (h *Handle) Cancel() bool {
+	if !h.state.CompareAndSwap(handlePending, handleCancelled) {
+		return false
+	}
+	close(h.done)
+	return true
+}
+
+// Done returns a channel closed once the action has run or was cancelled,
+// by Cancel or by its runner stopping, see Submit.
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Cancelled reports whether the action was withdrawn.
+func (h *Handle) Cancelled() bool {
+	return h.state.Load() == handleCancelled
+}
@@ -0,0 +1,81 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSubmit(t *testing.T) {
+	t.Run("Should run the action and close Done", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := false
+		h := action.Submit(r, func() {
+			ran = true
+		})
+		<-h.Done()
+		require.True(t, ran)
+		require.False(t, h.Cancel())
+		require.False(t, h.Cancelled())
+	})
+	t.Run("Should not run a cancelled action", func(t *testing.T) {
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		action.Tell(r, func() {
+			close(started)
+			<-release
+		})
+		<-started
+		ran := false
+		h := action.Submit(r, func() {
+			ran = true
+		})
+		require.True(t, h.Cancel())
+		require.False(t, h.Cancel())
+		<-h.Done()
+		close(release)
+		require.False(t, action.ActGet(r, func() bool {
+			return ran
+		}))
+		require.True(t, h.Cancelled())
+	})
+	t.Run("Should close Done once the runner stops", func(t *testing.T) {
+		for _, tc := range []struct {
+			title     string
+			opts      []func(*action.Runner)
+			cancelled bool
+		}{
+			{title: "without dead letters", cancelled: true},
+			{title: "with ignored dead letters", opts: []func(*action.Runner){action.WithDeadLetter(func(action.DeadLetter) {})}, cancelled: true},
+			{title: "with dead letters run by the sink", opts: []func(*action.Runner){action.WithDeadLetter(func(d action.DeadLetter) { d.Action() })}},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				r := action.New(append(tc.opts, action.WithChanSize(2), action.WithPanicCapture())...)
+				require.NoError(t, r.Start(t.Context()))
+				started, release := make(chan struct{}), make(chan struct{})
+				r.Send(func() {
+					close(started)
+					<-release
+					panic("boom")
+				})
+				<-started
+				ran := false
+				h := action.Submit(r, func() {
+					ran = true
+				})
+				close(release)
+				select {
+				case <-h.Done():
+				case <-time.After(time.Second):
+					t.Fatal("Done not closed")
+				}
+				<-r.Done()
+				require.Equal(t, tc.cancelled, h.Cancelled())
+				require.Equal(t, !tc.cancelled, ran)
+			})
+		}
+	})
+}
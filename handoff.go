@@ -0,0 +1,37 @@
+package action
+
+import "errors"
+
+// Handoff replaces from with to without dropping work, for instance to
+// restart an actor with a new configuration. Both runners must be started.
+//
+// Actions sent to from, and through the Actables and helpers built on it,
+// go to to from then on, and the names from is registered with now point to
+// to. The actions already queued on from run first, with to holding off
+// until they are done, so the actor state is never accessed concurrently.
+// from then stops, without error, and Handoff returns.
+func Handoff(from, to *Runner) error {
+	if from == to {
+		return errors.New("action: handoff to the same runner")
+	}
+	if !from.isStarted.Load() || !to.isStarted.Load() || from.loadCtx() == nil || to.loadCtx() == nil {
+		return ErrNotStarted
+	}
+	drained := make(chan struct{})
+	// Hold to until from is drained, then run what from could not.
+	to.Send(func() {
+		<-drained
+		for a := range from.Remaining() {
+			a()
+		}
+	})
+	from.redirect.Store(to)
+	reassign(from, to)
+	// If from is already stopping, its queue is left to to all the same.
+	from.enqueue(laneStream, func() {
+		from.handedOff = true
+	}, true)
+	<-from.done
+	close(drained)
+	return nil
+}
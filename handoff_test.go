@@ -0,0 +1,77 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHandoff(t *testing.T) {
+	t.Run("Should drain the old runner before the new one", func(t *testing.T) {
+		from := action.New(action.WithChanSize(10))
+		to := action.New()
+		require.NoError(t, from.Start(t.Context()))
+		require.NoError(t, to.Start(t.Context()))
+		a := action.NewRWActable(from, 0)
+		started, release := make(chan struct{}), make(chan struct{})
+		from.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		var order []string
+		for range 3 {
+			action.Tell(from, func() { order = append(order, "old") })
+		}
+		done := make(chan error)
+		go func() {
+			done <- action.Handoff(from, to)
+		}()
+		// Let Handoff redirect from while its queue is blocked.
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		require.NoError(t, <-done)
+		a.Set(1)
+		action.Act(from, func() { order = append(order, "new") })
+		require.Equal(t, 1, a.Get())
+		require.Equal(t, []string{"old", "old", "old", "new"}, order)
+		<-from.Done()
+		require.NoError(t, from.Error())
+	})
+	t.Run("Should move the registered names", func(t *testing.T) {
+		from, to := action.New(), action.New()
+		require.NoError(t, from.Start(t.Context()))
+		require.NoError(t, to.Start(t.Context()))
+		require.NoError(t, action.Register("handoff", from))
+		t.Cleanup(func() { action.Deregister("handoff") })
+		require.NoError(t, action.Handoff(from, to))
+		r, ok := action.Lookup("handoff")
+		require.True(t, ok)
+		require.Same(t, to, r)
+	})
+	t.Run("Should not run actions inline on the old runner", func(t *testing.T) {
+		from, to := action.New(action.WithInlineExecution()), action.New()
+		require.NoError(t, from.Start(t.Context()))
+		require.NoError(t, to.Start(t.Context()))
+		require.NoError(t, action.Handoff(from, to))
+		started, release := make(chan struct{}), make(chan struct{})
+		action.Tell(to, func() {
+			close(started)
+			<-release
+		})
+		<-started
+		ran := make(chan struct{})
+		go action.Act(from, func() { close(ran) })
+		select {
+		case <-ran:
+			t.Fatal("action ran alongside the new runner")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(release)
+		<-ran
+	})
+	t.Run("Should require started runners", func(t *testing.T) {
+		require.ErrorIs(t, action.Handoff(action.New(), action.New()), action.ErrNotStarted)
+	})
+}
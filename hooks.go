@@ -0,0 +1,55 @@
+package action
+
+import "context"
+
+// WithBeforeHook adds a hook called before each action is executed, on the
+// runner goroutine. An error stops the runner instead of running the
+// action, which is reported to the WithDeadLetter sink if any.
+func WithBeforeHook(h func(ctx context.Context) error) func(*Runner) {
+	return func(r *Runner) {
+		if h == nil {
+			r.invalid("nil before hook")
+			return
+		}
+		r.beforeHooks = append(r.beforeHooks, h)
+	}
+}
+
+// WithAfterHook adds a hook called after each action is executed. It is
+// WithHook, named after WithBeforeHook.
+func WithAfterHook(h func(ctx context.Context) error) func(*Runner) {
+	return WithHook(h)
+}
+
+// WithErrorHook adds a function called with the error that stopped the
+// runner, on the runner goroutine, before Done is closed. It is not called
+// if the runner stopped without error. Cancellations are errors too: the
+// error is ErrClosed after Close, or the cause of the Start context.
+func WithErrorHook(f func(err error)) func(*Runner) {
+	return func(r *Runner) {
+		if f == nil {
+			r.invalid("nil error hook")
+			return
+		}
+		r.errorHooks = append(r.errorHooks, f)
+	}
+}
+
+// runBeforeHooks calls the before hooks, returning the first error.
+func (r *Runner) runBeforeHooks(ctx context.Context) error {
+	for _, h := range r.beforeHooks {
+		if err := h(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runErrorHooks calls the error hooks if the runner stopped with an error.
+func (r *Runner) runErrorHooks() {
+	if err := r.Error(); err != nil {
+		for _, f := range r.errorHooks {
+			f(err)
+		}
+	}
+}
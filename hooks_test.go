@@ -0,0 +1,73 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWithBeforeHook(t *testing.T) {
+	t.Run("Should run around each action", func(t *testing.T) {
+		var log []string
+		r := action.New(action.WithBeforeHook(func(context.Context) error {
+			log = append(log, "before")
+			return nil
+		}), action.WithAfterHook(func(context.Context) error {
+			log = append(log, "after")
+			return nil
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() { log = append(log, "action") })
+		require.Equal(t, []string{"before", "action", "after"}, action.ActGet(r, func() []string { return log[:3] }))
+	})
+	t.Run("Should stop the runner instead of running the action", func(t *testing.T) {
+		errBefore := errors.New("before")
+		var letters []action.DeadLetter
+		r := action.New(action.WithBeforeHook(func(context.Context) error {
+			return errBefore
+		}), action.WithDeadLetter(func(l action.DeadLetter) {
+			letters = append(letters, l)
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		ran := false
+		r.Send(func() { ran = true })
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errBefore)
+		require.False(t, ran)
+		require.Len(t, letters, 1)
+		require.ErrorIs(t, letters[0].Reason, errBefore)
+	})
+	t.Run("Should reject a nil hook", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithBeforeHook(nil))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestWithErrorHook(t *testing.T) {
+	t.Run("Should get the error stopping the runner", func(t *testing.T) {
+		errHook := errors.New("hook")
+		var got error
+		r := action.New(action.WithHook(func(context.Context) error {
+			return errHook
+		}), action.WithErrorHook(func(err error) {
+			got = err
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {})
+		<-r.Done()
+		require.ErrorIs(t, got, errHook)
+	})
+	t.Run("Should get ErrClosed after Close", func(t *testing.T) {
+		errs := make(chan error, 1)
+		r := action.New(action.WithErrorHook(func(err error) { errs <- err }))
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		require.ErrorIs(t, <-errs, action.ErrClosed)
+	})
+	t.Run("Should reject a nil hook", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithErrorHook(nil))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
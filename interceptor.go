@@ -0,0 +1,101 @@
+package action
+
+import "time"
+
+// Middleware wraps the execution of every action of a runner, to log,
+// measure, recover or retry it. It is called on the runner goroutine and
+// must call next for the action to run.
+type Middleware func(next Action) Action
+
+// ActionEvent describes an executed action, see Observer.
+type ActionEvent struct {
+	// Started is when the action started.
+	Started time.Time
+	// Duration is how long the action and the hooks took.
+	Duration time.Duration
+	// Err is the error stopping the runner after the action, if any.
+	Err error
+}
+
+// Observer is notified after every action executed by a runner, on the
+// runner goroutine.
+type Observer func(ActionEvent)
+
+// RunnersV2 is implemented by runners accepting middlewares and observers,
+// so that custom implementations such as pools, remote or test runners get
+// them the same way as *Runner.
+type RunnersV2 interface {
+	Runners
+	// Use adds a middleware wrapping the next actions; the first added is
+	// the outermost.
+	Use(Middleware)
+	// Observe adds an observer of the next actions.
+	Observe(Observer)
+}
+
+var _ RunnersV2 = (*Runner)(nil)
+
+// WithMiddleware wraps every action of the runner with the middlewares, the
+// first being the outermost, like Use. It disables the inline execution
+// fast path.
+func WithMiddleware(middlewares ...Middleware) func(*Runner) {
+	return func(r *Runner) {
+		for _, m := range middlewares {
+			if m == nil {
+				r.invalid("nil middleware")
+				continue
+			}
+			r.Use(m)
+		}
+	}
+}
+
+// Use adds a middleware wrapping the actions executed from then on, after
+// the running one. It disables the inline execution fast path.
+func (r *Runner) Use(m Middleware) {
+	if m == nil {
+		return
+	}
+	r.intercept(func() {
+		r.middlewares = append(r.middlewares, m)
+	})
+}
+
+// Observe adds an observer of the actions executed from then on. It
+// disables the inline execution fast path.
+func (r *Runner) Observe(o Observer) {
+	if o == nil {
+		return
+	}
+	r.intercept(func() {
+		r.observers = append(r.observers, o)
+	})
+}
+
+// intercept applies f on the runner goroutine, or right away if the runner
+// is not started.
+func (r *Runner) intercept(f func()) {
+	r.intercepted.Store(true)
+	if !r.isStarted.Load() {
+		f()
+		return
+	}
+	r.command(f)
+}
+
+// Wrap returns action wrapped by the middlewares, the first being the
+// outermost.
+func Wrap(action Action, middlewares ...Middleware) Action {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		action = middlewares[i](action)
+	}
+	return action
+}
+
+// notify calls the observers for an action started at start.
+func (r *Runner) notify(start time.Time, err error) {
+	e := ActionEvent{Started: start, Duration: time.Since(start), Err: err}
+	for _, o := range r.observers {
+		o(e)
+	}
+}
@@ -0,0 +1,62 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/neonima/action/actiontest"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRunnersV2(t *testing.T) {
+	runners := map[string]func() action.RunnersV2{
+		"Runner":     func() action.RunnersV2 { return action.New(action.WithInlineExecution()) },
+		"SyncRunner": func() action.RunnersV2 { return actiontest.NewSyncRunner() },
+		"Pool":       func() action.RunnersV2 { return action.NewPool(1, action.RoundRobin) },
+	}
+	for name, newRunner := range runners {
+		t.Run("Should wrap and observe actions on a "+name, func(t *testing.T) {
+			r := newRunner()
+			require.NoError(t, r.Start(t.Context()))
+			var log []string
+			tag := func(s string) action.Middleware {
+				return func(next action.Action) action.Action {
+					return func() {
+						log = append(log, s+" in")
+						next()
+						log = append(log, s+" out")
+					}
+				}
+			}
+			r.Use(tag("outer"))
+			r.Use(tag("inner"))
+			observed := 0
+			r.Observe(func(action.ActionEvent) { observed++ })
+			action.Act(r, func() { log = append(log, "action") })
+			require.Equal(t, []string{"outer in", "inner in", "action", "inner out", "outer out"},
+				action.ActGet(r, func() []string { return log[:5] }))
+			require.Equal(t, 2, action.ActGet(r, func() int { return observed }))
+		})
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	t.Run("Should wrap every action", func(t *testing.T) {
+		var log []string
+		tag := func(s string) action.Middleware {
+			return func(next action.Action) action.Action {
+				return func() {
+					log = append(log, s)
+					next()
+				}
+			}
+		}
+		r := action.New(action.WithMiddleware(tag("outer"), tag("inner")), action.WithInlineExecution())
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() { log = append(log, "action") })
+		require.Equal(t, []string{"outer", "inner", "action"}, action.ActGet(r, func() []string { return log[:3] }))
+	})
+	t.Run("Should reject a nil middleware", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithMiddleware(nil))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
@@ -0,0 +1,50 @@
+package action
+
+import "sync"
+
+type runnerLocker struct {
+	r       *Runner
+	release chan struct{}
+}
+
+// AsLocker returns a sync.Locker whose Lock holds the runner: it returns once
+// every action queued before it has run, and no action runs until Unlock.
+// It eases migrating lock-based code to the runner one call site at a time.
+//
+// Like a sync.Mutex, it is not reentrant, and calling Act helpers on the same
+// runner while holding it deadlocks. Lock panics if the runner stops before
+// the lock is acquired. A runner stopping while the lock is held exits
+// without waiting for Unlock.
+func (r *Runner) AsLocker() sync.Locker {
+	return &runnerLocker{r: r}
+}
+
+func (l *runnerLocker) Lock() {
+	acquired := make(chan struct{})
+	release := make(chan struct{})
+	ctx := l.r.sendAwaited(func() {
+		close(acquired)
+		// A stopping runner must not wait for an Unlock to exit.
+		select {
+		case <-release:
+		case <-l.r.loadCtx().Done():
+		}
+	})
+	select {
+	case <-acquired:
+		l.release = release
+	case <-ctx.Done():
+		// The token may still be queued: let it through.
+		close(release)
+		panic("action: runner stopped while acquiring its lock")
+	}
+}
+
+func (l *runnerLocker) Unlock() {
+	release := l.release
+	if release == nil {
+		panic("action: unlock of unlocked runner lock")
+	}
+	l.release = nil
+	close(release)
+}
@@ -0,0 +1,82 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunner_AsLocker(t *testing.T) {
+	t.Run("Should be mutually exclusive with other lockers and actions", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		l := r.AsLocker()
+		n := 0
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				l.Lock()
+				defer l.Unlock()
+				n++
+			}()
+			go func() {
+				defer wg.Done()
+				action.Act(r, func() {
+					n++
+				})
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, 100, action.ActGet(r, func() int {
+			return n
+		}))
+	})
+	t.Run("Should panic on unlock of an unlocked locker", func(t *testing.T) {
+		r := action.New()
+		require.Panics(t, r.AsLocker().Unlock)
+	})
+	t.Run("Should let the runner stop after giving up the lock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		r := action.New(action.WithChanSize(2))
+		require.NoError(t, r.Start(ctx))
+		started, block := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-block
+		})
+		<-started
+		gaveUp := make(chan any)
+		go func() {
+			defer func() { gaveUp <- recover() }()
+			r.AsLocker().Lock()
+		}()
+		require.Eventually(t, func() bool { return r.Len() == 1 }, time.Second, time.Millisecond)
+		cancel()
+		require.NotNil(t, <-gaveUp)
+		close(block)
+		select {
+		case <-r.Done():
+		case <-time.After(time.Second):
+			t.Fatal("runner did not stop")
+		}
+	})
+	t.Run("Should let the runner stop while the lock is held", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		r := action.New()
+		require.NoError(t, r.Start(ctx))
+		l := r.AsLocker()
+		l.Lock()
+		cancel()
+		select {
+		case <-r.Done():
+		case <-time.After(time.Second):
+			t.Fatal("runner did not stop")
+		}
+		l.Unlock()
+	})
+}
@@ -0,0 +1,111 @@
+package action
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Managed owns a resource, such as a serial port or a prepared statement
+// cache, that must only be touched from a single goroutine. Every access
+// runs on the runner, and the resource is released when the runner stops.
+type Managed[T any] struct {
+	r       *Runner
+	res     T
+	open    func() (T, error)
+	release func(T) error
+	// live reports whether res is open, it is only changed on the runner.
+	live atomic.Bool
+	err  atomic.Pointer[error]
+}
+
+// NewManaged returns res owned by r. When r stops, release is called with
+// it; if release is nil and res implements io.Closer, it is closed instead.
+// Once released, Use and UseGet return ErrReleased, even if r is reset and
+// started again: use NewManagedFunc to reopen the resource on restarts.
+func NewManaged[T any](r *Runner, res T, release func(T) error) *Managed[T] {
+	m := &Managed[T]{r: r, res: res, release: closer(res, release)}
+	m.live.Store(true)
+	r.OnCleanup(m.releaseRes)
+	return m
+}
+
+// NewManagedFunc returns a resource owned by r, opened by open on the runner
+// by the first access after each start of r, and released like with
+// NewManaged each time r stops. It suits runners restarted with Reset, such
+// as the children of a Supervisor. An open error is returned by the access.
+func NewManagedFunc[T any](r *Runner, open func() (T, error), release func(T) error) *Managed[T] {
+	return &Managed[T]{r: r, open: open, release: release}
+}
+
+// closer returns release, or a function closing res if release is nil and
+// res implements io.Closer.
+func closer[T any](res T, release func(T) error) func(T) error {
+	if release == nil {
+		if c, ok := any(res).(io.Closer); ok {
+			return func(T) error { return c.Close() }
+		}
+	}
+	return release
+}
+
+// acquire returns the open resource, opening it if needed. It runs on the
+// runner.
+func (m *Managed[T]) acquire() (T, error) {
+	if m.live.Load() {
+		return m.res, nil
+	}
+	if m.open == nil {
+		var zero T
+		return zero, ErrReleased
+	}
+	res, err := m.open()
+	if err != nil {
+		return res, err
+	}
+	m.res = res
+	m.live.Store(true)
+	m.r.OnCleanup(m.releaseRes)
+	return res, nil
+}
+
+func (m *Managed[T]) releaseRes() {
+	if !m.live.CompareAndSwap(true, false) {
+		return
+	}
+	if release := closer(m.res, m.release); release != nil {
+		if err := release(m.res); err != nil {
+			m.err.Store(&err)
+		}
+	}
+}
+
+// Use calls f with the resource on the runner and returns its error.
+func (m *Managed[T]) Use(f func(T) error) error {
+	return ActErr(m.r, func() error {
+		res, err := m.acquire()
+		if err != nil {
+			return err
+		}
+		return f(res)
+	})
+}
+
+// UseGet calls f with the resource on the runner and returns its results.
+func UseGet[T, R any](m *Managed[T], f func(T) (R, error)) (R, error) {
+	return ActGetErr(m.r, func() (R, error) {
+		res, err := m.acquire()
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return f(res)
+	})
+}
+
+// Err returns the error releasing the resource, once the runner stopped.
+func (m *Managed[T]) Err() error {
+	if err := m.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package action_test
+
+import (
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type port struct {
+	writes []string
+	closed bool
+}
+
+func (p *port) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestManaged(t *testing.T) {
+	t.Run("Should run accesses on the runner", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		m := action.NewManaged(r, &port{}, nil)
+		require.NoError(t, m.Use(func(p *port) error {
+			p.writes = append(p.writes, "hello")
+			return nil
+		}))
+		n, err := action.UseGet(m, func(p *port) (int, error) {
+			return len(p.writes), nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	})
+	t.Run("Should close the resource when the runner stops", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		p := &port{}
+		action.NewManaged(r, p, nil)
+		require.NoError(t, r.Close())
+		require.True(t, p.closed)
+	})
+	t.Run("Should report the release error", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		releaseErr := errors.New("release")
+		m := action.NewManaged(r, 42, func(int) error { return releaseErr })
+		require.NoError(t, m.Err())
+		require.NoError(t, r.Close())
+		require.ErrorIs(t, m.Err(), releaseErr)
+	})
+	t.Run("Should refuse accesses once released", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		p := &port{}
+		m := action.NewManaged(r, p, nil)
+		require.NoError(t, r.Close())
+		require.NoError(t, r.Reset())
+		require.NoError(t, r.Start(t.Context()))
+		defer r.Close()
+		require.ErrorIs(t, m.Use(func(*port) error { return nil }), action.ErrReleased)
+		_, err := action.UseGet(m, func(*port) (int, error) { return 0, nil })
+		require.ErrorIs(t, err, action.ErrReleased)
+	})
+}
+
+func TestNewManagedFunc(t *testing.T) {
+	t.Run("Should reopen and release the resource on each start", func(t *testing.T) {
+		r := action.New()
+		var ports []*port
+		m := action.NewManagedFunc(r, func() (*port, error) {
+			ports = append(ports, &port{})
+			return ports[len(ports)-1], nil
+		}, nil)
+		for range 2 {
+			require.NoError(t, r.Start(t.Context()))
+			require.NoError(t, m.Use(func(p *port) error {
+				p.writes = append(p.writes, "hello")
+				return nil
+			}))
+			require.NoError(t, m.Use(func(*port) error { return nil }))
+			require.NoError(t, r.Close())
+			require.NoError(t, r.Reset())
+		}
+		require.Len(t, ports, 2)
+		for _, p := range ports {
+			require.True(t, p.closed)
+			require.Equal(t, []string{"hello"}, p.writes)
+		}
+	})
+	t.Run("Should return the open error", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		defer r.Close()
+		errOpen := errors.New("open")
+		m := action.NewManagedFunc(r, func() (int, error) { return 0, errOpen }, nil)
+		require.ErrorIs(t, m.Use(func(int) error { return nil }), errOpen)
+	})
+}
@@ -0,0 +1,32 @@
+package action
+
+// Number is the set of integer and floating-point types.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Add adds delta to the value of a on its runner and returns the result,
+// unlike a Get followed by a Set which can lose concurrent updates.
+func Add[N Number](a *RWActable[N], delta N) N {
+	return a.Update(func(v N) N {
+		return v + delta
+	})
+}
+
+// Max sets the value of a to v if v is greater, on its runner, and returns
+// the resulting value.
+func Max[N Number](a *RWActable[N], v N) N {
+	return a.Update(func(cur N) N {
+		return max(cur, v)
+	})
+}
+
+// Min sets the value of a to v if v is smaller, on its runner, and returns
+// the resulting value.
+func Min[N Number](a *RWActable[N], v N) N {
+	return a.Update(func(cur N) N {
+		return min(cur, v)
+	})
+}
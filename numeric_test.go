@@ -0,0 +1,39 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	t.Run("Should not lose concurrent additions", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		var wg sync.WaitGroup
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				action.Add(a, 2)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, 200, a.Get())
+	})
+}
+
+func TestMaxMin(t *testing.T) {
+	t.Run("Should keep the extreme values", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		hi := action.NewRWActable(r, 1.5)
+		require.Equal(t, 1.5, action.Max(hi, 0.5))
+		require.Equal(t, 3.0, action.Max(hi, 3))
+		lo := action.NewRWActable(r, uint8(10))
+		require.Equal(t, uint8(10), action.Min(lo, 20))
+		require.Equal(t, uint8(4), action.Min(lo, 4))
+	})
+}
@@ -0,0 +1,91 @@
+package action
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error of a runner stopped by a panicking action or
+// hook, with WithPanicCapture. Crash reporters get the panic value and the
+// stack of the goroutine at the time of the panic.
+type PanicError struct {
+	// Value is the value the action panicked with.
+	Value any
+	// Stack is the formatted stack trace of the panic, see debug.Stack.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("action: panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// WithPanicCapture makes a panicking action or hook stop the runner with a
+// *PanicError instead of crashing the process. The runner context is then
+// cancelled with it, so pending Act calls return. Actions run inline by
+// WithInlineExecution still panic on the caller's goroutine.
+func WithPanicCapture() func(*Runner) {
+	return func(r *Runner) {
+		r.capturePanics = true
+	}
+}
+
+// capture turns a panic into a *PanicError stored in err. It must be
+// deferred.
+func capture(err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Value: v, Stack: debug.Stack()}
+	}
+}
+
+// WithRecover makes the runner recover from a panicking action or hook,
+// report the panic value to f and keep processing the next actions. It
+// takes precedence over WithPanicCapture. The callers waiting for the
+// panicking action get a *PanicError from the helpers returning an error,
+// such as ActErr, and zero values from the others. Actions are not run
+// inline by WithInlineExecution, so that their panics are recovered too.
+func WithRecover(f func(recovered any)) func(*Runner) {
+	return func(r *Runner) {
+		if f == nil {
+			r.invalid("nil recover func")
+			return
+		}
+		r.onRecover = f
+	}
+}
+
+// recoverPanic reports a panic to the WithRecover callback. It must be
+// deferred.
+func (r *Runner) recoverPanic() {
+	if v := recover(); v != nil {
+		r.onRecover(v)
+	}
+}
+
+func (r *Runner) recovers() bool {
+	return r.onRecover != nil
+}
+
+// recovers reports whether r recovers from panicking actions, see
+// WithRecover.
+func recovers(r Runners) bool {
+	rc, ok := r.(interface{ recovers() bool })
+	return ok && rc.recovers()
+}
+
+// replyPanic returns a function to be deferred by an awaited action on a
+// runner recovering from panics. It gives the caller of the panicking
+// action its reply, then lets the panic through to the runner.
+func replyPanic(reply func(*PanicError)) func() {
+	return func() {
+		if v := recover(); v != nil {
+			reply(&PanicError{Value: v, Stack: debug.Stack()})
+			panic(v)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWithPanicCapture(t *testing.T) {
+	t.Run("Should stop the runner with a PanicError", func(t *testing.T) {
+		r := action.New(action.WithPanicCapture())
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {
+			panic("boom")
+		})
+		<-r.Done()
+		var pe *action.PanicError
+		require.ErrorAs(t, r.Error(), &pe)
+		require.Equal(t, "boom", pe.Value)
+		require.Contains(t, string(pe.Stack), "TestWithPanicCapture")
+	})
+	t.Run("Should cancel the runner context for pending callers", func(t *testing.T) {
+		r := action.New(action.WithPanicCapture())
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {
+			panic("boom")
+		})
+		<-r.Ctx().Done()
+		var pe *action.PanicError
+		require.ErrorAs(t, context.Cause(r.Ctx()), &pe)
+	})
+	t.Run("Should unwrap error values", func(t *testing.T) {
+		errPanic := errors.New("panic")
+		r := action.New(action.WithPanicCapture())
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {
+			panic(errPanic)
+		})
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errPanic)
+	})
+}
+
+func TestWithRecover(t *testing.T) {
+	t.Run("Should report the panic and keep running", func(t *testing.T) {
+		recovered := make(chan any, 1)
+		r := action.New(action.WithRecover(func(v any) {
+			recovered <- v
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {
+			panic("boom")
+		})
+		require.Equal(t, "boom", <-recovered)
+		require.Equal(t, 42, action.ActGet(r, func() int { return 42 }))
+		require.NoError(t, r.Error())
+	})
+	for _, tc := range []struct {
+		title string
+		opts  []func(*action.Runner)
+	}{
+		{title: "queued"},
+		{title: "inline", opts: []func(*action.Runner){action.WithInlineExecution()}},
+	} {
+		t.Run("Should give a PanicError to ActErr callers when "+tc.title, func(t *testing.T) {
+			recovered := make(chan any, 1)
+			r := action.New(append(tc.opts, action.WithRecover(func(v any) {
+				recovered <- v
+			}))...)
+			require.NoError(t, r.Start(t.Context()))
+			err := action.ActErr(r, func() error {
+				panic("boom")
+			})
+			var pe *action.PanicError
+			require.ErrorAs(t, err, &pe)
+			require.Equal(t, "boom", pe.Value)
+			require.Equal(t, "boom", <-recovered)
+			require.NoError(t, action.ActErr(r, func() error { return nil }))
+		})
+		t.Run("Should return from Act when "+tc.title, func(t *testing.T) {
+			recovered := make(chan any, 1)
+			r := action.New(append(tc.opts, action.WithRecover(func(v any) {
+				recovered <- v
+			}))...)
+			require.NoError(t, r.Start(t.Context()))
+			action.Act(r, func() {
+				panic("boom")
+			})
+			require.Equal(t, "boom", <-recovered)
+			require.Equal(t, 42, action.ActGet(r, func() int { return 42 }))
+		})
+	}
+	t.Run("Should reject a nil func", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithRecover(nil))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
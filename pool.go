@@ -0,0 +1,33 @@
+package action
+
+import (
+	"reflect"
+	"sync"
+)
+
+// replyPools holds a *sync.Pool of reply channels per result type.
+var replyPools sync.Map
+
+func replyPool[T any]() *sync.Pool {
+	key := reflect.TypeFor[T]()
+	if p, ok := replyPools.Load(key); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := replyPools.LoadOrStore(key, &sync.Pool{
+		New: func() any {
+			return make(chan T, 1)
+		},
+	})
+	return p.(*sync.Pool)
+}
+
+// getReply returns an empty reply channel with a buffer of 1.
+func getReply[T any]() chan T {
+	return replyPool[T]().Get().(chan T)
+}
+
+// putReply recycles a reply channel. It must only be called once the reply
+// has been received, a channel still awaited by an action must be dropped.
+func putReply[T any](c chan T) {
+	replyPool[T]().Put(c)
+}
@@ -0,0 +1,70 @@
+package action
+
+import (
+	"math"
+	"time"
+)
+
+// pressureTimeConstant is how fast Pressure follows the queue fill ratio:
+// after this long, about 63% of a change is reflected.
+const pressureTimeConstant = 250 * time.Millisecond
+
+// Pressure returns the fill ratio of the runner queue, between 0 and 1,
+// smoothed over time. Producers can shed or defer load as it rises, before
+// Send starts blocking.
+func (r *Runner) Pressure() float64 {
+	at := r.pressureAt.Load()
+	p := math.Float64frombits(r.pressure.Load())
+	if at == 0 {
+		return r.fill()
+	}
+	return smooth(p, r.fill(), time.Duration(time.Now().UnixNano()-at))
+}
+
+// WithPressureAlert calls f with true once Pressure rises to high, and with
+// false once it falls back to low, so producers can react to a filling
+// queue without polling. f runs on the runner goroutine and must not
+// block. Values must satisfy 0 <= low < high <= 1.
+func WithPressureAlert(high, low float64, f func(high bool)) func(*Runner) {
+	return func(r *Runner) {
+		if !(0 <= low && low < high && high <= 1) || f == nil {
+			r.invalid("pressure alert between %v and %v", low, high)
+			return
+		}
+		r.pressureHigh = high
+		r.pressureLow = low
+		r.onPressure = f
+	}
+}
+
+// samplePressure updates the smoothed pressure. It runs on the runner
+// goroutine, on every action.
+func (r *Runner) samplePressure() {
+	now := time.Now().UnixNano()
+	p := r.fill()
+	if at := r.pressureAt.Load(); at != 0 {
+		p = smooth(math.Float64frombits(r.pressure.Load()), p, time.Duration(now-at))
+	}
+	r.pressure.Store(math.Float64bits(p))
+	r.pressureAt.Store(now)
+	if r.onPressure == nil {
+		return
+	}
+	switch {
+	case !r.pressured && p >= r.pressureHigh:
+		r.pressured = true
+		r.onPressure(true)
+	case r.pressured && p <= r.pressureLow:
+		r.pressured = false
+		r.onPressure(false)
+	}
+}
+
+func (r *Runner) fill() float64 {
+	return float64(len(r.stream)) / float64(cap(r.stream))
+}
+
+// smooth moves prev toward current according to the time elapsed.
+func smooth(prev, current float64, elapsed time.Duration) float64 {
+	return current + (prev-current)*math.Exp(-float64(elapsed)/float64(pressureTimeConstant))
+}
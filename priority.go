@@ -0,0 +1,128 @@
+package action
+
+// WithPriority adds levels priority lanes of size actions each on top of
+// the default one, so latency-sensitive actions sent with SendPriority or
+// ActPriority jump ahead of bulk work. Level 0 is the default lane used by
+// Send, and higher levels run first. Within a level, actions keep their
+// order. Size 0 means the WithChanSize capacity.
+func WithPriority(levels, size int) func(*Runner) {
+	return func(r *Runner) {
+		if levels < 1 || size < 0 {
+			r.invalid("priority with %d levels of size %d", levels, size)
+			return
+		}
+		// Sized by New if 0.
+		r.priority = make([]chan Action, levels)
+		for i := range r.priority {
+			r.priority[i] = make(chan Action, size)
+		}
+		r.prioritized = make(chan struct{}, 1)
+	}
+}
+
+// prioritySender is implemented by runners with priority lanes, see
+// WithPriority.
+type prioritySender interface {
+	SendPriority(level int, a Action)
+}
+
+// SendPriority enqueues an action at the given level, see WithPriority.
+// Levels are clamped to the configured ones, so without WithPriority it is
+// Send.
+func (r *Runner) SendPriority(level int, a Action) {
+	if to := r.redirect.Load(); to != nil {
+		to.SendPriority(level, a)
+		return
+	}
+	if level <= 0 || len(r.priority) == 0 {
+		r.Send(a)
+		return
+	}
+	if _, ok := r.enqueue(min(level, len(r.priority))-1, a, true); !ok {
+		if to := r.redirect.Load(); to != nil {
+			to.SendPriority(level, a)
+			return
+		}
+		panic(stopped(r.Ctx()))
+	}
+	select {
+	case r.prioritized <- struct{}{}:
+	default:
+	}
+}
+
+// nextPriority returns the first action of the highest non-empty priority
+// lane, if any.
+func (r *Runner) nextPriority() (Action, bool) {
+	for i := len(r.priority) - 1; i >= 0; i-- {
+		select {
+		case a, ok := <-r.priority[i]:
+			if ok {
+				return a, true
+			}
+		default:
+		}
+	}
+	return nil, false
+}
+
+// sendPriority enqueues an action at the given level if r has priority
+// lanes.
+func sendPriority(r Runners, level int, action Action) {
+	if ps, ok := r.(prioritySender); ok {
+		ps.SendPriority(level, action)
+		return
+	}
+	r.Send(action)
+}
+
+// ActPriority is Act with the action sent at the given level, see
+// WithPriority.
+func ActPriority(r Runners, level int, action Action) {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		action()
+		return
+	}
+	c := getReply[struct{}]()
+	sendPriority(r, level, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- struct{}{} })()
+		}
+		action()
+		c <- struct{}{}
+	})
+	ctx := r.Ctx()
+	select {
+	case <-ctx.Done():
+		return
+	case <-c:
+		putReply(c)
+		return
+	}
+}
+
+// ActGetPriority is ActGet with the action sent at the given level, see
+// WithPriority.
+func ActGetPriority[T any](r Runners, level int, action ActionReturn[T]) T {
+	if ir, ok := acquireInline(r); ok {
+		defer ir.releaseInline()
+		return action()
+	}
+	c := getReply[T]()
+	sendPriority(r, level, func() {
+		if recovers(r) {
+			defer replyPanic(func(pe *PanicError) { c <- *new(T) })()
+		}
+		c <- action()
+	})
+	ctx := r.Ctx()
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero
+	case v := <-c:
+		putReply(c)
+		return v
+	}
+}
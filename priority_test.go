@@ -0,0 +1,72 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWithPriority(t *testing.T) {
+	t.Run("Should size the lanes after WithChanSize whatever the order", func(t *testing.T) {
+		r := action.New(action.WithPriority(2, 0), action.WithChanSize(8))
+		for range 8 {
+			r.SendPriority(1, func() {})
+			r.SendPriority(2, func() {})
+		}
+		require.Equal(t, 16, r.Len())
+	})
+	t.Run("Should run higher levels first", func(t *testing.T) {
+		r := action.New(action.WithChanSize(8), action.WithPriority(2, 0))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		var got []int
+		r.Send(func() { got = append(got, 0) })
+		r.SendPriority(1, func() { got = append(got, 1) })
+		r.SendPriority(2, func() { got = append(got, 2) })
+		r.SendPriority(5, func() { got = append(got, 3) })
+		close(release)
+		require.Equal(t, []int{2, 3, 1, 0}, action.ActGetPriority(r, 0, func() []int { return got }))
+	})
+	t.Run("Should wait for the action", func(t *testing.T) {
+		r := action.New(action.WithPriority(1, 1))
+		require.NoError(t, r.Start(t.Context()))
+		ran := false
+		action.ActPriority(r, 1, func() { ran = true })
+		require.True(t, ran)
+		require.Equal(t, 42, action.ActGetPriority(r, 1, func() int { return 42 }))
+	})
+	t.Run("Should fall back to Send without priority lanes", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.Equal(t, 42, action.ActGetPriority(r, 3, func() int { return 42 }))
+	})
+	t.Run("Should yield the remaining actions by priority", func(t *testing.T) {
+		stop := false
+		r := action.New(action.WithChanSize(4), action.WithPriority(1, 0), action.WithHook(func(context.Context) error {
+			if stop {
+				return context.Canceled
+			}
+			return nil
+		}))
+		var got []int
+		r.SendPriority(1, func() { stop = true })
+		r.Send(func() { got = append(got, 0) })
+		r.SendPriority(1, func() { got = append(got, 1) })
+		require.NoError(t, r.Start(t.Context()))
+		<-r.Done()
+		for a := range r.Remaining() {
+			a()
+		}
+		require.Equal(t, []int{1, 0}, got)
+	})
+	t.Run("Should reject invalid levels", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithPriority(0, 1))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
@@ -0,0 +1,75 @@
+package action
+
+import (
+	"context"
+	"time"
+)
+
+// quiescePoll is how long Quiesce waits between two checks of a busy runner.
+const quiescePoll = time.Millisecond
+
+// Quiesce returns once the runner queue is empty and no action is running,
+// or with the context error. It returns right away if the runner stopped.
+// It checks the runner with actions, sent like any other.
+func (r *Runner) Quiesce(ctx context.Context) error {
+	return r.QuiesceFor(ctx, 0)
+}
+
+// QuiesceFor is like Quiesce but also requires the runner to stay idle for
+// settle, so that actions sent in response to the last ones are waited for.
+func (r *Runner) QuiesceFor(ctx context.Context, settle time.Duration) error {
+	type probe struct {
+		queued   int
+		executed uint64
+	}
+	check := func() (probe, error) {
+		var p probe
+		err := ActWithContext(ctx, r, func(context.Context) error {
+			p = probe{queued: r.queued(), executed: r.executed}
+			return nil
+		})
+		return p, err
+	}
+	wait := func(d time.Duration) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.done:
+			return nil
+		case <-t.C:
+			return nil
+		}
+	}
+	for {
+		select {
+		case <-r.done:
+			return nil
+		default:
+		}
+		p, err := check()
+		if err != nil {
+			return err
+		}
+		if p.queued == 0 {
+			if settle <= 0 {
+				return nil
+			}
+			if err := wait(settle); err != nil {
+				return err
+			}
+			// Only the next probe may have run meanwhile.
+			q, err := check()
+			if err != nil {
+				return err
+			}
+			if q.queued == 0 && q.executed <= p.executed+1 {
+				return nil
+			}
+		}
+		if err := wait(quiescePoll); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRunner_Quiesce(t *testing.T) {
+	t.Run("Should wait for chained actions", func(t *testing.T) {
+		r := action.New(action.WithChanSize(4))
+		require.NoError(t, r.Start(t.Context()))
+		n := 0
+		var step func()
+		step = func() {
+			if n++; n < 50 {
+				time.Sleep(100 * time.Microsecond)
+				action.Tell(r, step)
+			}
+		}
+		action.Tell(r, step)
+		require.NoError(t, r.Quiesce(t.Context()))
+		require.Equal(t, 50, action.ActGet(r, func() int { return n }))
+	})
+	t.Run("Should wait for the runner to settle", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		n := 0
+		go func() {
+			for range 5 {
+				time.Sleep(2 * time.Millisecond)
+				action.Tell(r, func() { n++ })
+			}
+		}()
+		require.NoError(t, r.QuiesceFor(t.Context(), 20*time.Millisecond))
+		require.Equal(t, 5, action.ActGet(r, func() int { return n }))
+	})
+	t.Run("Should give up with the context", func(t *testing.T) {
+		r := action.New(action.WithChanSize(4))
+		require.NoError(t, r.Start(t.Context()))
+		release := make(chan struct{})
+		defer close(release)
+		r.Send(func() { <-release })
+		r.Send(func() {})
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, r.Quiesce(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,81 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Rate is a number of actions allowed per period, as a token bucket
+// refilled continuously and holding at most Actions tokens.
+type Rate struct {
+	Actions int
+	Per     time.Duration
+}
+
+type quota struct {
+	key    func(context.Context) string
+	limits map[string]Rate
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// WithQuota rate limits per tenant the calls given the caller's context,
+// ActWithContext and ActGetErrCtx along with ActCtx, ActGetCtx and
+// ActErrCtx: key returns the tenant of the caller's context, and limits its
+// rate. Calls beyond the rate fail with ErrQuotaExceeded without being
+// queued, so one tenant cannot monopolize a shared runner. Tenants missing
+// from limits are not limited.
+func WithQuota(key func(ctx context.Context) string, limits map[string]Rate) func(*Runner) {
+	return func(r *Runner) {
+		if key == nil {
+			r.invalid("nil quota key")
+			return
+		}
+		for k, l := range limits {
+			if l.Actions <= 0 || l.Per <= 0 {
+				r.invalid("quota of %d actions per %s for %q", l.Actions, l.Per, k)
+				return
+			}
+		}
+		r.quota = &quota{key: key, limits: limits, buckets: make(map[string]*bucket)}
+	}
+}
+
+func (r *Runner) admit(ctx context.Context) error {
+	if r.quota == nil {
+		return nil
+	}
+	return r.quota.admit(ctx)
+}
+
+// admit takes a token from the bucket of the caller's tenant.
+func (q *quota) admit(ctx context.Context) error {
+	k := q.key(ctx)
+	l, ok := q.limits[k]
+	if !ok {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	b, ok := q.buckets[k]
+	if !ok {
+		b = &bucket{tokens: float64(l.Actions), last: now}
+		q.buckets[k] = b
+	}
+	b.tokens = min(float64(l.Actions), b.tokens+now.Sub(b.last).Seconds()*float64(l.Actions)/l.Per.Seconds())
+	b.last = now
+	if b.tokens < 1 {
+		return fmt.Errorf("%w: %q", ErrQuotaExceeded, k)
+	}
+	b.tokens--
+	return nil
+}
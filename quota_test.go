@@ -0,0 +1,77 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type quotaTenantKey struct{}
+
+func TestWithQuota(t *testing.T) {
+	tenant := func(ctx context.Context) string {
+		s, _ := ctx.Value(quotaTenantKey{}).(string)
+		return s
+	}
+	t.Run("Should limit each tenant independently", func(t *testing.T) {
+		r := action.New(action.WithQuota(tenant, map[string]action.Rate{
+			"noisy": {Actions: 2, Per: time.Hour},
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		noisy := context.WithValue(t.Context(), quotaTenantKey{}, "noisy")
+		quiet := context.WithValue(t.Context(), quotaTenantKey{}, "quiet")
+		noop := func(context.Context) error { return nil }
+		require.NoError(t, action.ActWithContext(noisy, r, noop))
+		require.NoError(t, action.ActWithContext(noisy, r, noop))
+		require.ErrorIs(t, action.ActWithContext(noisy, r, noop), action.ErrQuotaExceeded)
+		for range 5 {
+			require.NoError(t, action.ActWithContext(quiet, r, noop))
+		}
+	})
+	t.Run("Should refill over time", func(t *testing.T) {
+		r := action.New(action.WithQuota(tenant, map[string]action.Rate{
+			"": {Actions: 1, Per: 10 * time.Millisecond},
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		noop := func(context.Context) error { return nil }
+		require.NoError(t, action.ActWithContext(t.Context(), r, noop))
+		require.ErrorIs(t, action.ActWithContext(t.Context(), r, noop), action.ErrQuotaExceeded)
+		time.Sleep(15 * time.Millisecond)
+		require.NoError(t, action.ActWithContext(t.Context(), r, noop))
+	})
+	for name, call := range map[string]func(context.Context, action.Runners) error{
+		"ActWithContext": func(ctx context.Context, r action.Runners) error {
+			return action.ActWithContext(ctx, r, func(context.Context) error { return nil })
+		},
+		"ActGetErrCtx": func(ctx context.Context, r action.Runners) error {
+			_, err := action.ActGetErrCtx(ctx, r, func() (int, error) { return 0, nil })
+			return err
+		},
+		"ActGetCtx": func(ctx context.Context, r action.Runners) error {
+			_, err := action.ActGetCtx(ctx, r, func() int { return 0 })
+			return err
+		},
+		"ActErrCtx": func(ctx context.Context, r action.Runners) error {
+			return action.ActErrCtx(ctx, r, func() error { return nil })
+		},
+		"ActCtx": func(ctx context.Context, r action.Runners) error {
+			return action.ActCtx(ctx, r, func() {})
+		},
+	} {
+		t.Run("Should limit "+name, func(t *testing.T) {
+			r := action.New(action.WithQuota(tenant, map[string]action.Rate{
+				"noisy": {Actions: 1, Per: time.Hour},
+			}))
+			require.NoError(t, r.Start(t.Context()))
+			noisy := context.WithValue(t.Context(), quotaTenantKey{}, "noisy")
+			require.NoError(t, call(noisy, r))
+			require.ErrorIs(t, call(noisy, r), action.ErrQuotaExceeded)
+		})
+	}
+	t.Run("Should reject invalid rates", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithQuota(tenant, map[string]action.Rate{"a": {}}))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
@@ -0,0 +1,205 @@
+package action
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type registration struct {
+	r Runners
+	// stop releases the watch of the runner, see watch.
+	stop func()
+}
+
+var registry = struct {
+	sync.RWMutex
+	names map[string]*registration
+}{names: make(map[string]*registration)}
+
+// Register makes r reachable under name with Lookup. Runners exposing a
+// Done channel, such as *Runner, are deregistered once they stop, or stop
+// being watched once deregistered with Deregister.
+// It returns ErrNameTaken if the name is already registered.
+func Register(name string, r Runners) error {
+	reg := &registration{r: r}
+	registry.Lock()
+	if _, ok := registry.names[name]; ok {
+		registry.Unlock()
+		return ErrNameTaken
+	}
+	registry.names[name] = reg
+	registry.Unlock()
+
+	watch(name, reg)
+	return nil
+}
+
+// watch deregisters reg once its runner stops, if it exposes a Done channel.
+// Runners exposing DoneCtx, such as *Runner, are watched without a goroutine.
+func watch(name string, reg *registration) {
+	stop := func() {}
+	switch d := reg.r.(type) {
+	case interface{ DoneCtx() context.Context }:
+		release := context.AfterFunc(d.DoneCtx(), func() {
+			deregister(name, reg)
+		})
+		stop = func() { release() }
+	case interface{ Done() <-chan struct{} }:
+		quit := make(chan struct{})
+		go func() {
+			select {
+			case <-d.Done():
+				deregister(name, reg)
+			case <-quit:
+			}
+		}()
+		stop = sync.OnceFunc(func() { close(quit) })
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	reg.stop = stop
+	// Deregistered before being watched.
+	if registry.names[name] != reg {
+		reg.release()
+	}
+}
+
+// reassign registers to under the names from is registered with.
+func reassign(from, to Runners) {
+	registry.Lock()
+	var moved map[string]*registration
+	for name, reg := range registry.names {
+		if reg.r == from {
+			if moved == nil {
+				moved = make(map[string]*registration)
+			}
+			reg.release()
+			moved[name] = &registration{r: to}
+			registry.names[name] = moved[name]
+		}
+	}
+	registry.Unlock()
+	for name, reg := range moved {
+		watch(name, reg)
+	}
+}
+
+// Deregister removes the runner registered under name, if any.
+func Deregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	if reg, ok := registry.names[name]; ok {
+		reg.release()
+		delete(registry.names, name)
+	}
+}
+
+func deregister(name string, reg *registration) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.names[name] == reg {
+		reg.release()
+		delete(registry.names, name)
+	}
+}
+
+// deregisterRunner removes name if it is still registered to r.
+func deregisterRunner(name string, r Runners) {
+	registry.Lock()
+	defer registry.Unlock()
+	if reg, ok := registry.names[name]; ok && reg.r == r {
+		reg.release()
+		delete(registry.names, name)
+	}
+}
+
+// release stops watching the runner. It is called with the registry locked.
+func (reg *registration) release() {
+	if reg.stop != nil {
+		reg.stop()
+	}
+}
+
+// Lookup returns the runner registered under name.
+func Lookup(name string) (Runners, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	reg, ok := registry.names[name]
+	if !ok {
+		return nil, false
+	}
+	return reg.r, true
+}
+
+// Range calls f for each registered runner, in name order, stopping if f
+// returns false. It iterates over a snapshot, so f may register and
+// deregister runners.
+func Range(f func(name string, r Runners) bool) {
+	type entry struct {
+		name string
+		r    Runners
+	}
+	registry.RLock()
+	entries := make([]entry, 0, len(registry.names))
+	for name, reg := range registry.names {
+		entries = append(entries, entry{name, reg.r})
+	}
+	registry.RUnlock()
+	slices.SortFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.name, b.name)
+	})
+	for _, e := range entries {
+		if !f(e.name, e.r) {
+			return
+		}
+	}
+}
+
+// WithName makes Start register the runner under name, see Register, so it
+// can be found with Lookup until it stops. The name is released before Done
+// is closed, so the runner can be reset and started again right away. Start
+// returns ErrNameTaken if the name is already registered.
+func WithName(name string) func(*Runner) {
+	return func(r *Runner) {
+		if name == "" {
+			r.invalid("empty name")
+			return
+		}
+		r.name = name
+	}
+}
+
+// Name returns the name given with WithName, if any.
+func (r *Runner) Name() string {
+	return r.name
+}
+
+// singletons holds a *sync.Mutex per Singleton name.
+var singletons sync.Map
+
+// Singleton returns the runner registered under name, creating and
+// registering it with factory on first use. Concurrent callers wait for the
+// same creation. If factory fails, the error is returned and the next call
+// tries again; once the runner stops, the next call creates a new one.
+func Singleton(name string, factory func() (Runners, error)) (Runners, error) {
+	if r, ok := Lookup(name); ok {
+		return r, nil
+	}
+	m, _ := singletons.LoadOrStore(name, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+	if r, ok := Lookup(name); ok {
+		return r, nil
+	}
+	r, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	if err := Register(name, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
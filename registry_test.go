@@ -0,0 +1,170 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("Should make the runner reachable by name", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, action.Register("register-lookup", r))
+		t.Cleanup(func() { action.Deregister("register-lookup") })
+		got, ok := action.Lookup("register-lookup")
+		require.True(t, ok)
+		require.Equal(t, r, got)
+	})
+	t.Run("Should refuse a name already taken", func(t *testing.T) {
+		require.NoError(t, action.Register("register-taken", action.New()))
+		t.Cleanup(func() { action.Deregister("register-taken") })
+		require.ErrorIs(t, action.Register("register-taken", action.New()), action.ErrNameTaken)
+	})
+	t.Run("Should deregister the runner once stopped", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		require.NoError(t, action.Register("register-stop", r))
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := action.Lookup("register-stop")
+			return !ok
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Should not spawn a goroutine per registration", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		for i := range 100 {
+			require.NoError(t, action.Register(fmt.Sprintf("register-many-%d", i), action.New()))
+		}
+		require.Less(t, runtime.NumGoroutine(), before+10)
+		for i := range 100 {
+			action.Deregister(fmt.Sprintf("register-many-%d", i))
+		}
+	})
+	t.Run("Should keep a new registration when the deregistered runner stops", func(t *testing.T) {
+		old := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, old.Start(ctx))
+		require.NoError(t, action.Register("register-again", old))
+		action.Deregister("register-again")
+		r := action.New()
+		require.NoError(t, action.Register("register-again", r))
+		t.Cleanup(func() { action.Deregister("register-again") })
+		cancel()
+		<-old.Done()
+		got, ok := action.Lookup("register-again")
+		require.True(t, ok)
+		require.Equal(t, r, got)
+	})
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("Should report unknown names", func(t *testing.T) {
+		_, ok := action.Lookup("unknown")
+		require.False(t, ok)
+	})
+}
+
+func TestSingleton(t *testing.T) {
+	t.Run("Should create the runner once", func(t *testing.T) {
+		t.Cleanup(func() { action.Deregister("singleton-once") })
+		var created atomic.Int32
+		factory := func() (action.Runners, error) {
+			created.Add(1)
+			r := action.New()
+			return r, r.Start(t.Context())
+		}
+		var wg sync.WaitGroup
+		runners := make([]action.Runners, 10)
+		for i := range runners {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runners[i], _ = action.Singleton("singleton-once", factory)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(1), created.Load())
+		for _, r := range runners {
+			require.Same(t, runners[0], r)
+		}
+	})
+	t.Run("Should retry after a factory error", func(t *testing.T) {
+		t.Cleanup(func() { action.Deregister("singleton-retry") })
+		boom := errors.New("boom")
+		_, err := action.Singleton("singleton-retry", func() (action.Runners, error) {
+			return nil, boom
+		})
+		require.ErrorIs(t, err, boom)
+		r, err := action.Singleton("singleton-retry", func() (action.Runners, error) {
+			return action.New(), nil
+		})
+		require.NoError(t, err)
+		require.NotNil(t, r)
+	})
+}
+
+func TestWithName(t *testing.T) {
+	t.Run("Should register the runner on start", func(t *testing.T) {
+		r := action.New(action.WithName("with-name"))
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		require.NoError(t, r.Start(ctx))
+		require.Equal(t, "with-name", r.Name())
+		got, ok := action.Lookup("with-name")
+		require.True(t, ok)
+		require.Equal(t, r, got)
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := action.Lookup("with-name")
+			return !ok
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Should refuse to start under a name already taken", func(t *testing.T) {
+		require.NoError(t, action.Register("with-name-taken", action.New()))
+		t.Cleanup(func() { action.Deregister("with-name-taken") })
+		r := action.New(action.WithName("with-name-taken"))
+		require.ErrorIs(t, r.Start(t.Context()), action.ErrNameTaken)
+	})
+	t.Run("Should reject an empty name", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithName(""))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestRange(t *testing.T) {
+	t.Run("Should visit runners in name order", func(t *testing.T) {
+		for _, name := range []string{"range-b", "range-a", "range-c"} {
+			require.NoError(t, action.Register(name, action.New()))
+			t.Cleanup(func() { action.Deregister(name) })
+		}
+		var names []string
+		action.Range(func(name string, _ action.Runners) bool {
+			if strings.HasPrefix(name, "range-") {
+				names = append(names, name)
+			}
+			return true
+		})
+		require.Equal(t, []string{"range-a", "range-b", "range-c"}, names)
+	})
+	t.Run("Should stop when f returns false", func(t *testing.T) {
+		require.NoError(t, action.Register("range-stop-a", action.New()))
+		t.Cleanup(func() { action.Deregister("range-stop-a") })
+		require.NoError(t, action.Register("range-stop-b", action.New()))
+		t.Cleanup(func() { action.Deregister("range-stop-b") })
+		var calls int
+		action.Range(func(string, action.Runners) bool {
+			calls++
+			return false
+		})
+		require.Equal(t, 1, calls)
+	})
+}
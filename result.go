@@ -0,0 +1,43 @@
+package action
+
+// Result holds the outcome of an operation that returns a value or fails,
+// so it can travel through a single channel, like the reply of an
+// asynchronous action.
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok returns a successful result holding v.
+func Ok[T any](v T) Result[T] {
+	return Result[T]{value: v}
+}
+
+// Err returns a failed result holding err.
+func Err[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// ResultOf returns the result of a function returning a value and an error.
+func ResultOf[T any](v T, err error) Result[T] {
+	return Result[T]{value: v, err: err}
+}
+
+// Unwrap returns the value and the error of the result.
+func (r Result[T]) Unwrap() (T, error) {
+	return r.value, r.err
+}
+
+// IsOk reports whether the result holds no error.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Map returns the result of f applied to the value of r, or the error of r
+// if it failed, in which case f is not called.
+func Map[T, U any](r Result[T], f func(T) U) Result[U] {
+	if r.err != nil {
+		return Err[U](r.err)
+	}
+	return Ok(f(r.value))
+}
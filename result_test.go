@@ -0,0 +1,37 @@
+package action_test
+
+import (
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	t.Run("Should hold a value", func(t *testing.T) {
+		v, err := action.Ok(42).Unwrap()
+		require.NoError(t, err)
+		require.Equal(t, 42, v)
+		require.True(t, action.Ok(42).IsOk())
+	})
+	t.Run("Should hold an error", func(t *testing.T) {
+		errResult := errors.New("result")
+		r := action.Err[int](errResult)
+		require.False(t, r.IsOk())
+		_, err := r.Unwrap()
+		require.ErrorIs(t, err, errResult)
+	})
+	t.Run("Should map values and keep errors", func(t *testing.T) {
+		v, err := action.Map(action.ResultOf(strconv.Atoi("41")), func(n int) int { return n + 1 }).Unwrap()
+		require.NoError(t, err)
+		require.Equal(t, 42, v)
+		called := false
+		_, err = action.Map(action.ResultOf(strconv.Atoi("x")), func(n int) int {
+			called = true
+			return n
+		}).Unwrap()
+		require.Error(t, err)
+		require.False(t, called)
+	})
+}
@@ -2,8 +2,15 @@ package action
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Runners interface {
@@ -12,14 +19,114 @@ type Runners interface {
 	Ctx() context.Context
 }
 
+var _ io.Closer = (*Runner)(nil)
+
+// cacheLineSize is the padding keeping fields written by different
+// goroutines on separate cache lines.
+const cacheLineSize = 64
+
 type Runner struct {
-	stream    chan Action
-	isStarted atomic.Bool
-	ctx       context.Context
-	hooks     []func(context.Context) error
-	done      chan struct{}
-	err       atomic.Pointer[error]
+	// Read-mostly fields, set by the options and Start.
+	name          string
+	stream        chan Action
+	urgent        chan Action
+	priority      []chan Action
+	prioritized   chan struct{}
+	control       chan func()
+	ctx           atomic.Pointer[context.Context]
+	hooks         []hook
+	beforeHooks   []func(context.Context) error
+	errorHooks    []func(error)
+	hasHooks      atomic.Bool
+	hookSeq       uint64
+	done          chan struct{}
+	isStarted     atomic.Bool
+	inline        bool
+	idle          time.Duration
+	hookBatch     int
+	hookInterval  time.Duration
+	timeout       time.Duration
+	ctxKeys       []any
+	middlewares   []Middleware
+	observers     []Observer
+	intercepted   atomic.Bool
+	quota         *quota
+	doneCtx       context.Context
+	cancelDone    context.CancelCauseFunc
+	telemetry     Telemetry
+	pressureHigh  float64
+	pressureLow   float64
+	onPressure    func(bool)
+	tick          time.Duration
+	onTick        func(context.Context) error
+	redirect      atomic.Pointer[Runner]
+	turn          int
+	capturePanics bool
+	deadLetter    func(DeadLetter)
+	onRecover     func(any)
+	warmup        func(context.Context) error
+	warm          atomic.Bool
+	cancel        context.CancelCauseFunc
+	_             [cacheLineSize]byte
+
+	// Written by producers on every Send.
+	pending atomic.Int64
+	_       [cacheLineSize - 8]byte
+
+	// Written by the runner goroutine on every action.
+	mu         sync.Mutex
+	unhooked   int
+	lastHooks  time.Time
+	running    atomic.Bool
+	pressure   atomic.Uint64
+	pressureAt atomic.Int64
+	pressured  bool
+	dropping   error
+	handedOff  bool
+	executed   uint64
+	_          [cacheLineSize]byte
+
+	// Written once when the runner stops.
+	err atomic.Pointer[error]
+
+	// Invalid options, reported by NewChecked.
+	optErrs []error
+
+	stopMu sync.Mutex
+	stopOn []context.Context
+
+	cleanupMu sync.Mutex
+	cleanups  []func()
+	cleanedUp bool
+
+	jobsMu sync.Mutex
+	jobs   map[string]*Job
+
+	// lanesMu guards the queues against being closed or replaced while a
+	// send is in flight, see push.
+	lanesMu     sync.RWMutex
+	lanesClosed bool
+	// stopping is closed once the runner stops, before its queues are.
+	stopping chan struct{}
+	// parked is closed when a supervised runner restarts, see Supervisor.
+	parked     chan struct{}
+	supervised bool
+
 	sync.Once
+	exited sync.WaitGroup
+}
+
+type hookBatchKey struct{}
+
+type hook struct {
+	id uint64
+	fn func(context.Context) error
+}
+
+// HookHandle identifies a hook added with AddHook.
+type HookHandle struct {
+	r  *Runner
+	id uint64
 }
 
 // New returns a new Runner with default configuration settings.
@@ -28,22 +135,57 @@ type Runner struct {
 //   - stream channel capacity: 1
 func New(opts ...func(*Runner)) *Runner {
 	r := &Runner{
-		stream: make(chan Action, 1),
-		done:   make(chan struct{}, 1),
+		stream:   make(chan Action, 1),
+		control:  make(chan func(), 1),
+		done:     make(chan struct{}, 1),
+		stopping: make(chan struct{}),
 	}
+	r.doneCtx, r.cancelDone = context.WithCancelCause(context.Background())
 
 	for _, opt := range opts {
 		opt(r)
 	}
+	// Lanes of size 0 take the WithChanSize capacity, whatever the options
+	// order.
+	if r.urgent != nil && cap(r.urgent) == 0 {
+		r.urgent = make(chan Action, cap(r.stream))
+	}
+	for i, c := range r.priority {
+		if cap(c) == 0 {
+			r.priority[i] = make(chan Action, cap(r.stream))
+		}
+	}
 
 	return r
 }
 
+// NewChecked is like New but reports invalid options instead of ignoring
+// them: non-positive sizes and durations, nil hooks, and options cancelling
+// each other. The error wraps ErrInvalidOption for each of them.
+func NewChecked(opts ...func(*Runner)) (*Runner, error) {
+	r := New(opts...)
+	if r.inline && len(r.hooks)+len(r.beforeHooks) > 0 {
+		r.invalid("inline execution is disabled by hooks")
+	}
+	if (r.hookBatch > 0 || r.hookInterval > 0) && len(r.hooks) == 0 {
+		r.invalid("hook batch without hooks")
+	}
+	if err := errors.Join(r.optErrs...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Runner) invalid(format string, args ...any) {
+	r.optErrs = append(r.optErrs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOption}, args...)...))
+}
+
 // WithChanSize defines a specific chan size for the actor buffer message queue
 // default is 1. If 0, will be ignored.
 func WithChanSize(size int) func(*Runner) {
 	return func(r *Runner) {
 		if size <= 0 {
+			r.invalid("chan size %d", size)
 			return
 		}
 		r.stream = make(chan Action, size)
@@ -56,10 +198,267 @@ func WithChanSize(size int) func(*Runner) {
 func WithHook(h func(ctx context.Context) error) func(*Runner) {
 	return func(r *Runner) {
 		if h == nil {
+			r.invalid("nil hook")
+			return
+		}
+
+		r.addHook(h)
+	}
+}
+
+func (r *Runner) addHook(h func(context.Context) error) uint64 {
+	r.hookSeq++
+	r.hooks = append(r.hooks, hook{id: r.hookSeq, fn: h})
+	r.hasHooks.Store(true)
+	return r.hookSeq
+}
+
+// AddHook adds a hook to a started runner, see WithHook. It takes effect
+// before the actions already queued, once the current one is done. The
+// returned handle removes it.
+func (r *Runner) AddHook(h func(ctx context.Context) error) HookHandle {
+	if h == nil {
+		return HookHandle{}
+	}
+	var id uint64
+	r.command(func() {
+		id = r.addHook(h)
+	})
+	return HookHandle{r: r, id: id}
+}
+
+// Remove removes the hook from the runner, before the actions already
+// queued. It does nothing if the hook was already removed.
+func (h HookHandle) Remove() {
+	if h.r == nil {
+		return
+	}
+	r := h.r
+	r.command(func() {
+		hooks := r.hooks[:0]
+		for _, e := range r.hooks {
+			if e.id != h.id {
+				hooks = append(hooks, e)
+			}
+		}
+		clear(r.hooks[len(hooks):])
+		r.hooks = hooks
+		r.hasHooks.Store(len(hooks) > 0)
+	})
+}
+
+// command runs f on the runner goroutine ahead of the queued actions and
+// waits for it. It is meant for lifecycle commands, which must take effect
+// promptly however deep the queue is; hooks do not run after them. It
+// reports false if the runner stopped before running f.
+func (r *Runner) command(f func()) bool {
+	ran := make(chan struct{})
+	select {
+	case r.control <- func() {
+		f()
+		close(ran)
+	}:
+	case <-r.done:
+		return false
+	}
+	if r.idle > 0 && r.isStarted.Load() {
+		r.wake()
+	}
+	select {
+	case <-ran:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// runCommand runs a command received on the control lane.
+func (r *Runner) runCommand(f func()) {
+	if r.inline {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	f()
+}
+
+// WithTick calls onTick on the runner goroutine every interval, between
+// actions, for housekeeping such as compaction or flushing metrics. Like a
+// hook error, an error returned by onTick stops the runner. Ticks do not
+// keep a runner with an idle timeout awake, and are skipped while it is
+// parked. If 0, will be ignored.
+func WithTick(interval time.Duration, onTick func(ctx context.Context) error) func(*Runner) {
+	return func(r *Runner) {
+		if interval <= 0 || onTick == nil {
+			r.invalid("tick every %s", interval)
+			return
+		}
+		r.tick = interval
+		r.onTick = onTick
+	}
+}
+
+// WithWarmup calls f on the runner goroutine before it takes the first
+// action, to load caches or state before serving. Actions sent meanwhile are
+// queued, and Send blocks once the queue is full. If f fails, the runner
+// stops with its error without running any action.
+func WithWarmup(f func(ctx context.Context) error) func(*Runner) {
+	return func(r *Runner) {
+		if f == nil {
+			r.invalid("nil warmup")
+			return
+		}
+		r.warmup = f
+	}
+}
+
+// runWarmup calls the warmup function the first time the goroutine starts.
+func (r *Runner) runWarmup(ctx context.Context) error {
+	if r.warmup == nil || r.warm.Load() {
+		return nil
+	}
+	defer r.warm.Store(true)
+	if r.inline {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	return r.warmup(ctx)
+}
+
+// WithMaxActionsPerTurn makes the runner yield after n consecutive actions:
+// it checks its context, runs a due tick, lets the pending commands such as
+// AddHook in and lets other goroutines run, before taking the next action.
+// It bounds how long a flooded queue delays cancellation. If 0, will be
+// ignored.
+func WithMaxActionsPerTurn(n int) func(*Runner) {
+	return func(r *Runner) {
+		if n <= 0 {
+			r.invalid("max actions per turn %d", n)
+			return
+		}
+		r.turn = n
+	}
+}
+
+// runTick calls the tick function, holding the execution lock like actions.
+func (r *Runner) runTick(ctx context.Context) error {
+	if r.inline {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	return r.onTick(ctx)
+}
+
+// WithInlineExecution lets the Act helpers run the action directly on the
+// caller's goroutine when the runner is idle, saving the round trip through
+// the queue. Actions remain mutually exclusive and ordered after anything
+// already queued. The fast path is skipped when hooks or WithRecover are
+// configured, and once the runner is handed off.
+func WithInlineExecution() func(*Runner) {
+	return func(r *Runner) {
+		r.inline = true
+	}
+}
+
+// WithReplyPriority gives the actions a caller is blocked on, those sent by
+// the Act helpers, priority over the ones sent with Send, Tell or Submit.
+// It improves the latency of interactive reads queued behind bulk writes,
+// at the cost of ordering: an Act call may run before a Tell sent earlier,
+// even from the same goroutine. At most size awaited actions are queued at
+// once, 0 meaning the WithChanSize capacity.
+func WithReplyPriority(size int) func(*Runner) {
+	return func(r *Runner) {
+		if size < 0 {
+			r.invalid("reply priority queue size %d", size)
 			return
 		}
+		// Sized by New if 0.
+		r.urgent = make(chan Action, size)
+	}
+}
 
-		r.hooks = append(r.hooks, h)
+func (r *Runner) sendAwaited(a Action) context.Context {
+	if to := r.redirect.Load(); to != nil {
+		return to.sendAwaited(a)
+	}
+	l := laneStream
+	if r.urgent != nil {
+		l = laneUrgent
+	}
+	ctx, ok := r.enqueue(l, a, true)
+	if !ok {
+		if to := r.redirect.Load(); to != nil {
+			return to.sendAwaited(a)
+		}
+		panic(stopped(r.Ctx()))
+	}
+	return ctx
+}
+
+// WithActionTimeout sets how long the context given to ActWithContext
+// actions lasts, so actions doing I/O can bail out instead of blocking the
+// runner. If 0, will be ignored.
+func WithActionTimeout(d time.Duration) func(*Runner) {
+	return func(r *Runner) {
+		if d <= 0 {
+			r.invalid("action timeout %s", d)
+			return
+		}
+		r.timeout = d
+	}
+}
+
+func (r *Runner) actionTimeout() time.Duration {
+	return r.timeout
+}
+
+// WithContextKeys makes ActWithContext carry the values of the caller's
+// context for the given keys, such as trace or tenant IDs, into the context
+// given to the action, so request metadata survives the hop onto the runner.
+func WithContextKeys(keys ...any) func(*Runner) {
+	return func(r *Runner) {
+		r.ctxKeys = append(r.ctxKeys, keys...)
+	}
+}
+
+func (r *Runner) contextKeys() []any {
+	return r.ctxKeys
+}
+
+// WithHookBatch makes the hooks run once every n actions, or on the first
+// action once interval has elapsed since they last ran, instead of after
+// every action. Either can be 0 to be ignored. Hooks get the number of
+// actions executed since their last call with HookBatchCount.
+func WithHookBatch(n int, interval time.Duration) func(*Runner) {
+	return func(r *Runner) {
+		if n < 0 || interval < 0 {
+			r.invalid("hook batch of %d actions or %s", n, interval)
+		}
+		r.hookBatch = max(n, 0)
+		r.hookInterval = max(interval, 0)
+		r.lastHooks = time.Now()
+	}
+}
+
+// HookBatchCount returns the number of actions executed since the hooks last
+// ran. To be called from a hook; it is always 1 without WithHookBatch.
+func HookBatchCount(ctx context.Context) int {
+	if n, ok := ctx.Value(hookBatchKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
+
+// WithIdleTimeout makes the runner goroutine exit after being idle for d,
+// and start again on the next Send. The goroutine is only started by the
+// first Send. Useful for processes holding many mostly idle runners.
+// If 0, will be ignored.
+func WithIdleTimeout(d time.Duration) func(*Runner) {
+	return func(r *Runner) {
+		if d <= 0 {
+			r.invalid("idle timeout %s", d)
+			return
+		}
+		r.idle = d
 	}
 }
 
@@ -68,49 +467,539 @@ func (r *Runner) Start(ctx context.Context) error {
 	if r.isStarted.Load() {
 		return ErrAlreadyStarted
 	}
+	if r.name != "" && ctx != nil {
+		if err := Register(r.name, r); err != nil {
+			return err
+		}
+	}
 	r.isStarted.Store(true)
 	if ctx == nil {
 		return ErrNilContext
 	}
-	r.ctx = ctx
+	r.stopMu.Lock()
+	ctx, r.cancel = context.WithCancelCause(ctx)
+	for _, c := range r.stopOn {
+		r.stopWhen(c)
+	}
+	r.stopMu.Unlock()
+	r.ctx.Store(&ctx)
+	r.unpark()
+	if r.idle > 0 {
+		// The goroutine must run to observe the cancellation.
+		context.AfterFunc(ctx, r.wake)
+		if r.queued() > 0 {
+			r.wake()
+		}
+		return nil
+	}
+	r.running.Store(true)
+	r.exited.Add(1)
 	go r.start(ctx)
 	return nil
 }
 
+// StopOn ties the runner to ctx besides the context given to Start: the
+// runner stops, like when that context is cancelled, once ctx is done, with
+// its cause as the runner error. It can be called before or after Start, as
+// many times as there are cancellation sources. The ties are kept by Reset.
+func (r *Runner) StopOn(ctx context.Context) {
+	r.stopMu.Lock()
+	defer r.stopMu.Unlock()
+	r.stopOn = append(r.stopOn, ctx)
+	if r.cancel != nil {
+		r.stopWhen(ctx)
+	}
+}
+
+func (r *Runner) stopWhen(ctx context.Context) {
+	cancel := r.cancel
+	stop := context.AfterFunc(ctx, func() {
+		cancel(context.Cause(ctx))
+	})
+	r.OnCleanup(func() { stop() })
+}
+
+// CloseTimeout is how long Close waits for the runner to stop.
+var CloseTimeout = 5 * time.Second
+
+// Close stops the runner and waits up to CloseTimeout for its goroutine to
+// exit, so a Runner can be used as an io.Closer. The runner error is
+// ErrClosed unless it had already stopped. Close does nothing if the runner
+// was never started.
+func (r *Runner) Close() error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel(ErrClosed)
+	t := time.NewTimer(CloseTimeout)
+	defer t.Stop()
+	select {
+	case <-r.done:
+		return nil
+	case <-t.C:
+		return fmt.Errorf("runner still running after %s: %w", CloseTimeout, context.DeadlineExceeded)
+	}
+}
+
+// Stop asks the runner to stop, with ErrClosed as its error unless it had
+// already stopped, without waiting for it: use Done or Close for that.
+// Stop does nothing if the runner was never started.
+func (r *Runner) Stop() {
+	if r.cancel != nil {
+		r.cancel(ErrClosed)
+	}
+}
+
+// Reset makes a stopped runner startable again, keeping its options, hooks
+// and StopOn ties, so the values built over it do not need to be recreated.
+// Actions still queued are dropped: drain Remaining first to keep them. The
+// OnCleanup functions ran when it stopped and are not kept, so resources
+// tied to a generation, such as NewManaged ones, stay released; see
+// NewManagedFunc. It returns
+// ErrNotStopped if the runner is still running. Reset must not be called
+// concurrently with the other methods, except the sends.
+func (r *Runner) Reset() error {
+	if !r.isStarted.Load() {
+		return nil
+	}
+	select {
+	case <-r.done:
+	default:
+		return ErrNotStopped
+	}
+	// Wait for the stopping goroutine to leave the Once before replacing it.
+	r.exited.Wait()
+	r.lanesMu.Lock()
+	r.stream = make(chan Action, cap(r.stream))
+	if r.urgent != nil {
+		r.urgent = make(chan Action, cap(r.urgent))
+	}
+	for i, c := range r.priority {
+		r.priority[i] = make(chan Action, cap(c))
+	}
+	r.stopping = make(chan struct{})
+	// Parked senders are let in by Start, once the context is set.
+	r.lanesClosed = r.parked != nil
+	r.lanesMu.Unlock()
+	r.done = make(chan struct{}, 1)
+	r.doneCtx, r.cancelDone = context.WithCancelCause(context.Background())
+	r.cancel = nil
+	r.err.Store(nil)
+	r.redirect.Store(nil)
+	r.handedOff, r.dropping = false, nil
+	r.unhooked, r.pressured = 0, false
+	r.pressure.Store(0)
+	r.pending.Store(0)
+	r.running.Store(false)
+	r.warm.Store(false)
+	r.cleanupMu.Lock()
+	r.cleanups, r.cleanedUp = nil, false
+	r.cleanupMu.Unlock()
+	r.Once = sync.Once{}
+	r.isStarted.Store(false)
+	return nil
+}
+
+// wake starts the runner goroutine if it is not running.
+func (r *Runner) wake() {
+	ctx := r.loadCtx()
+	if ctx == nil {
+		return
+	}
+	if r.running.CompareAndSwap(false, true) {
+		r.exited.Add(1)
+		go r.start(ctx)
+	}
+}
+
 func (r *Runner) start(ctx context.Context) {
-	defer func() {
-		r.Once.Do(func() {
-			close(r.stream)
-			close(r.done)
-		})
-	}()
+	defer r.exited.Done()
+	if err := r.runWarmup(ctx); err != nil {
+		r.setErr(err)
+	} else if r.run(ctx) {
+		return
+	}
+	r.Once.Do(func() {
+		r.closeLanes()
+		r.dropQueued()
+		r.cleanup()
+		r.runErrorHooks()
+		// Unblock the callers waiting on a runner stopped by an error.
+		if err := r.Error(); err != nil && r.cancel != nil {
+			r.cancel(err)
+		}
+		// The name is free once Done is closed, so the runner can restart.
+		if r.name != "" {
+			deregisterRunner(r.name, r)
+		}
+		r.cancelDone(r.Error())
+		close(r.done)
+	})
+}
+
+// closeLanes closes the queues once the senders in flight are done, so
+// later sends fail instead of panicking. Those to a supervised runner are
+// parked until it restarts.
+func (r *Runner) closeLanes() {
+	close(r.stopping)
+	r.lanesMu.Lock()
+	defer r.lanesMu.Unlock()
+	close(r.stream)
+	if r.urgent != nil {
+		close(r.urgent)
+	}
+	for _, c := range r.priority {
+		close(c)
+	}
+	r.lanesClosed = true
+	if r.supervised {
+		r.parked = make(chan struct{})
+	}
+}
+
+// unpark lets in the senders parked while the runner was stopped.
+func (r *Runner) unpark() {
+	r.lanesMu.Lock()
+	defer r.lanesMu.Unlock()
+	if r.parked != nil {
+		r.lanesClosed = false
+		close(r.parked)
+		r.parked = nil
+	}
+}
+
+// supervise makes the senders of the runner park while it is stopped,
+// instead of failing, until it restarts. Turning it off lets the parked
+// senders fail.
+func (r *Runner) supervise(on bool) {
+	r.lanesMu.Lock()
+	defer r.lanesMu.Unlock()
+	r.supervised = on
+	if !on && r.parked != nil {
+		close(r.parked)
+		r.parked = nil
+	}
+}
+
+// run executes actions until the runner stops or, with an idle timeout,
+// parks. It reports whether the goroutine parked.
+func (r *Runner) run(ctx context.Context) bool {
+	var idle <-chan time.Time
+	var timer *time.Timer
+	if r.idle > 0 {
+		timer = time.NewTimer(r.idle)
+		defer timer.Stop()
+		idle = timer.C
+	}
+	turn := 0
+	var tick <-chan time.Time
+	if r.tick > 0 {
+		ticker := time.NewTicker(r.tick)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 	for {
+		var action Action
+		ok := false
 		select {
-		case <-ctx.Done():
-			err := ctx.Err()
-			r.err.Store(&err)
-			return
-		case action, ok := <-r.stream:
+		case f := <-r.control:
+			r.runCommand(f)
+			continue
+		default:
+		}
+		if r.urgent != nil {
+			select {
+			case action, ok = <-r.urgent:
+			default:
+			}
+		}
+		if !ok && r.priority != nil {
+			action, ok = r.nextPriority()
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				r.setErr(context.Cause(ctx))
+				return false
+			case <-idle:
+				r.running.Store(false)
+				// A Send may have raced with parking, keep going if so.
+				if r.queued() == 0 || !r.running.CompareAndSwap(false, true) {
+					return true
+				}
+				timer.Reset(r.idle)
+				continue
+			case f := <-r.control:
+				r.runCommand(f)
+				continue
+			case <-tick:
+				if err := r.runTick(ctx); err != nil {
+					r.setErr(err)
+					return false
+				}
+				continue
+			case <-r.prioritized:
+				continue
+			case action, ok = <-r.urgent:
+			case action, ok = <-r.stream:
+			}
 			if !ok {
-				return
+				return false
 			}
-			action()
-			for _, h := range r.hooks {
-				if err := h(ctx); err != nil {
-					r.err.Store(&err)
-					return
+		}
+		r.samplePressure()
+		r.executed++
+		var err error
+		if r.telemetry != nil {
+			err = r.executeMeasured(ctx, action)
+		} else {
+			err = r.execute(ctx, action)
+		}
+		if err != nil {
+			r.setErr(err)
+			return false
+		}
+		if r.handedOff {
+			return false
+		}
+		if timer != nil {
+			timer.Reset(r.idle)
+		}
+		if r.turn > 0 {
+			if turn++; turn >= r.turn {
+				turn = 0
+				if err := r.yield(ctx, tick); err != nil {
+					r.setErr(err)
+					return false
 				}
 			}
 		}
 	}
 }
 
+// yield lets the cancellation, a tick and the pending commands in, after
+// WithMaxActionsPerTurn consecutive actions. It returns the error stopping
+// the runner, if any.
+func (r *Runner) yield(ctx context.Context, tick <-chan time.Time) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	select {
+	case <-tick:
+		if err := r.runTick(ctx); err != nil {
+			return err
+		}
+	default:
+	}
+	for {
+		select {
+		case f := <-r.control:
+			r.runCommand(f)
+		default:
+			runtime.Gosched()
+			return nil
+		}
+	}
+}
+
+// Len returns the number of actions waiting in the queues.
+func (r *Runner) Len() int {
+	r.lanesMu.RLock()
+	defer r.lanesMu.RUnlock()
+	return r.queued()
+}
+
+// queued returns the number of actions and commands waiting in the queues.
+func (r *Runner) queued() int {
+	n := len(r.stream) + len(r.urgent) + len(r.control)
+	for _, c := range r.priority {
+		n += len(c)
+	}
+	return n
+}
+
+// execute runs the action and the hooks.
+func (r *Runner) execute(ctx context.Context, action Action) (err error) {
+	if len(r.observers) > 0 {
+		start := time.Now()
+		defer func() { r.notify(start, err) }()
+	}
+	original := action
+	if len(r.middlewares) > 0 {
+		action = Wrap(action, r.middlewares...)
+	}
+	if r.capturePanics {
+		defer capture(&err)
+	}
+	if r.onRecover != nil {
+		defer r.recoverPanic()
+	}
+	if r.inline {
+		r.mu.Lock()
+		defer func() {
+			r.mu.Unlock()
+			r.pending.Add(-1)
+		}()
+	}
+	if err := r.runBeforeHooks(ctx); err != nil {
+		if r.deadLetter != nil {
+			// The action is lettered, it reports itself as dropped.
+			r.dropping = err
+			original()
+		}
+		return err
+	}
+	action()
+	return r.runHooks(ctx)
+}
+
+// runHooks calls the hooks, once per batch if WithHookBatch is set.
+func (r *Runner) runHooks(ctx context.Context) error {
+	if len(r.hooks) == 0 {
+		return nil
+	}
+	if r.hookBatch > 0 || r.hookInterval > 0 {
+		r.unhooked++
+		full := r.hookBatch > 0 && r.unhooked >= r.hookBatch
+		expired := r.hookInterval > 0 && time.Since(r.lastHooks) >= r.hookInterval
+		if !full && !expired {
+			return nil
+		}
+		ctx = context.WithValue(ctx, hookBatchKey{}, r.unhooked)
+		r.unhooked = 0
+		r.lastHooks = time.Now()
+	}
+	for _, h := range r.hooks {
+		if err := h.fn(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// acquireInline takes the execution lock if nothing is queued or executing,
+// so the caller can run an action on its own goroutine. It must be followed
+// by releaseInline when it succeeds.
+func (r *Runner) acquireInline() bool {
+	if !r.inline || r.hasHooks.Load() || len(r.beforeHooks) > 0 || r.onRecover != nil || r.intercepted.Load() || r.telemetry != nil || !r.isStarted.Load() || r.pending.Load() != 0 ||
+		(r.warmup != nil && !r.warm.Load()) {
+		return false
+	}
+	if !r.mu.TryLock() {
+		return false
+	}
+	// A Handoff marks its end with an action, so holding the lock, a
+	// redirect is seen once from is done.
+	if ctx := r.loadCtx(); r.pending.Load() != 0 || ctx == nil || ctx.Err() != nil || r.redirect.Load() != nil {
+		r.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+func (r *Runner) releaseInline() {
+	r.mu.Unlock()
+}
+
 // Done returns a channel closed when the runner is stopped.
 func (r *Runner) Done() <-chan struct{} {
 	return r.done
 }
 
+func (r *Runner) setErr(err error) {
+	r.err.Store(&err)
+}
+
+// OnCleanup registers f to be called once when the runner stops, before
+// Done is closed. Functions run in reverse registration order on the runner
+// goroutine, like deferred calls. If the runner has already stopped, f runs
+// right away. It can be called from actions to release what they acquired.
+func (r *Runner) OnCleanup(f func()) {
+	r.cleanupMu.Lock()
+	if !r.cleanedUp {
+		r.cleanups = append(r.cleanups, f)
+		r.cleanupMu.Unlock()
+		return
+	}
+	r.cleanupMu.Unlock()
+	f()
+}
+
+func (r *Runner) cleanup() {
+	r.cleanupMu.Lock()
+	r.cleanedUp = true
+	cleanups := r.cleanups
+	r.cleanups = nil
+	r.cleanupMu.Unlock()
+	for _, f := range slices.Backward(cleanups) {
+		f()
+	}
+}
+
+// Remaining yields the actions still queued when the runner stopped, in
+// order, awaited ones first with WithReplyPriority, then the ones of the
+// highest WithPriority levels, so they can be persisted or sent to another
+// runner. Each action is yielded once across calls. It yields nothing until
+// Done is closed.
+func (r *Runner) Remaining() iter.Seq[Action] {
+	return func(yield func(Action) bool) {
+		select {
+		case <-r.done:
+		default:
+			return
+		}
+		r.queuedActions()(yield)
+	}
+}
+
+// queuedActions yields the actions of the closed queues, see Remaining.
+func (r *Runner) queuedActions() iter.Seq[Action] {
+	return func(yield func(Action) bool) {
+		if r.urgent != nil {
+			for a := range r.urgent {
+				if !yield(a) {
+					return
+				}
+			}
+		}
+		for _, c := range slices.Backward(r.priority) {
+			for a := range c {
+				if !yield(a) {
+					return
+				}
+			}
+		}
+		for a := range r.stream {
+			if !yield(a) {
+				return
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the runner error, possibly nil,
+// once the runner is stopped, and then closed. Each call returns a new
+// channel, so any number of listeners can wait for the shutdown.
+func (r *Runner) Subscribe() <-chan error {
+	c := make(chan error, 1)
+	context.AfterFunc(r.doneCtx, func() {
+		c <- r.Error()
+		close(c)
+	})
+	return c
+}
+
+// DoneCtx returns a context cancelled when the runner is stopped, with the
+// runner error as its cause. It lets code select on the runner termination
+// like on any other context.
+func (r *Runner) DoneCtx() context.Context {
+	return r.doneCtx
+}
+
 // Err returns the error of the runner. To be used with Done()
+//
+// When the runner stopped because its context was cancelled, this is the
+// cancellation cause, see context.Cause.
 func (r *Runner) Error() error {
 	errPtr := r.err.Load()
 	if errPtr == nil {
@@ -121,11 +1010,134 @@ func (r *Runner) Error() error {
 
 // Send enqueues an action onto the actor's queue.
 // It is exported to support custom implementations, but direct use is discouraged. See action.go for examples, which should suffice in most cases.
+//
+// Send panics if the runner is stopped, see SendErr.
 func (r *Runner) Send(a Action) {
-	r.stream <- a
+	if err := r.SendErr(a); err != nil {
+		panic(err)
+	}
 }
 
-// Ctx returns the context
+// SendErr is like Send, but returns an error wrapping ErrStopped instead of
+// panicking if the runner is stopped, so it can be called by code that may
+// outlive the runner, such as timers or request handlers.
+func (r *Runner) SendErr(a Action) error {
+	if to := r.redirect.Load(); to != nil {
+		return to.SendErr(a)
+	}
+	if _, ok := r.enqueue(laneStream, a, true); !ok {
+		// A Handoff may have completed meanwhile.
+		if to := r.redirect.Load(); to != nil {
+			return to.SendErr(a)
+		}
+		return stopped(r.Ctx())
+	}
+	return nil
+}
+
+// Lanes of enqueue, besides the WithPriority levels counted from 0.
+const (
+	laneStream = -1
+	laneUrgent = -2
+)
+
+// lane returns the queue of l. It must be called holding lanesMu.
+func (r *Runner) lane(l int) chan Action {
+	switch l {
+	case laneStream:
+		return r.stream
+	case laneUrgent:
+		return r.urgent
+	}
+	return r.priority[l]
+}
+
+// enqueue sends a to lane l, blocking while it is full unless block is
+// false. It returns the runner context a was queued under, and reports
+// false if a was not sent.
+func (r *Runner) enqueue(l int, a Action, block bool) (context.Context, bool) {
+	if r.deadLetter != nil {
+		a = r.letter(a)
+	}
+	if r.inline {
+		r.pending.Add(1)
+	}
+	ctx, ok := r.push(l, a, block)
+	if !ok {
+		if r.inline {
+			r.pending.Add(-1)
+		}
+		return nil, false
+	}
+	if r.telemetry != nil {
+		r.telemetry.Count(MetricSent, 1)
+	}
+	if r.idle > 0 && r.isStarted.Load() {
+		r.wake()
+	}
+	return ctx, true
+}
+
+// push sends a to lane l, holding lanesMu so the queues are not closed or
+// replaced meanwhile, and returns the runner context then. The senders of a
+// stopped supervised runner are parked until it restarts. It reports false
+// if a was not sent.
+func (r *Runner) push(l int, a Action, block bool) (context.Context, bool) {
+	for {
+		r.lanesMu.RLock()
+		if !r.lanesClosed {
+			ctx, sent := r.loadCtx(), false
+			if block {
+				select {
+				case r.lane(l) <- a:
+					sent = true
+				case <-r.stopping:
+				}
+			} else {
+				select {
+				case r.lane(l) <- a:
+					sent = true
+				default:
+				}
+			}
+			r.lanesMu.RUnlock()
+			if sent || !block {
+				return ctx, sent
+			}
+			// Stopping, wait for the queues to be closed.
+			runtime.Gosched()
+			continue
+		}
+		parked := r.parked
+		r.lanesMu.RUnlock()
+		if parked == nil || !block {
+			return nil, false
+		}
+		<-parked
+	}
+}
+
+// TrySend is like Send but returns false right away instead of blocking
+// when the queue is full, so callers can shed or defer the load.
+func (r *Runner) TrySend(a Action) bool {
+	if to := r.redirect.Load(); to != nil {
+		return to.TrySend(a)
+	}
+	_, ok := r.enqueue(laneStream, a, false)
+	return ok
+}
+
+// Ctx returns the context, the one of the new runner after a Handoff.
 func (r *Runner) Ctx() context.Context {
-	return r.ctx
+	if to := r.redirect.Load(); to != nil {
+		return to.Ctx()
+	}
+	return r.loadCtx()
+}
+
+func (r *Runner) loadCtx() context.Context {
+	if ctx := r.ctx.Load(); ctx != nil {
+		return *ctx
+	}
+	return nil
 }
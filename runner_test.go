@@ -3,9 +3,15 @@ package action_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/neonima/action"
 	"github.com/stretchr/testify/require"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -92,3 +98,732 @@ func TestRunner_WithHook(t *testing.T) {
 		require.Equal(t, -1, incr)
 	})
 }
+
+func TestRunner_WithInlineExecution(t *testing.T) {
+	onCaller := func() bool {
+		buf := make([]byte, 4096)
+		return !strings.Contains(string(buf[:runtime.Stack(buf, false)]), "(*Runner).start")
+	}
+	t.Run("Should execute on the caller's goroutine when idle", func(t *testing.T) {
+		r := action.New(action.WithInlineExecution())
+		require.NoError(t, r.Start(t.Context()))
+		require.True(t, action.ActGet(r, onCaller))
+	})
+	t.Run("Should execute on the runner without the option", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.False(t, action.ActGet(r, onCaller))
+	})
+	t.Run("Should execute on the runner when hooks are set", func(t *testing.T) {
+		r := action.New(action.WithInlineExecution(), action.WithHook(func(context.Context) error {
+			return nil
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		require.False(t, action.ActGet(r, onCaller))
+	})
+	t.Run("Should run after already queued actions", func(t *testing.T) {
+		r := action.New(action.WithInlineExecution())
+		require.NoError(t, r.Start(t.Context()))
+		var order []int
+		action.Tell(r, func() {
+			time.Sleep(10 * time.Millisecond)
+			order = append(order, 1)
+		})
+		action.Act(r, func() {
+			order = append(order, 2)
+		})
+		require.Equal(t, []int{1, 2}, order)
+	})
+}
+
+func TestRunner_WithIdleTimeout(t *testing.T) {
+	parked := func(r *action.Runner) func() bool {
+		return func() bool {
+			buf := make([]byte, 1<<20)
+			return !strings.Contains(string(buf[:runtime.Stack(buf, true)]), fmt.Sprintf("(*Runner).run(%p", r))
+		}
+	}
+	t.Run("Should not start the goroutine before the first action", func(t *testing.T) {
+		r := action.New(action.WithIdleTimeout(time.Millisecond))
+		require.NoError(t, r.Start(t.Context()))
+		time.Sleep(10 * time.Millisecond)
+		require.True(t, parked(r)())
+		require.Equal(t, 1, action.ActGet(r, func() int {
+			return 1
+		}))
+	})
+	t.Run("Should park when idle and wake up on send", func(t *testing.T) {
+		r := action.New(action.WithIdleTimeout(time.Millisecond))
+		require.NoError(t, r.Start(t.Context()))
+		n := 0
+		action.Act(r, func() {
+			n++
+		})
+		require.Eventually(t, parked(r), time.Second, time.Millisecond)
+		action.Act(r, func() {
+			n++
+		})
+		require.Equal(t, 2, action.ActGet(r, func() int {
+			return n
+		}))
+	})
+	t.Run("Should stop while parked", func(t *testing.T) {
+		r := action.New(action.WithIdleTimeout(time.Millisecond))
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		cancel()
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), context.Canceled)
+	})
+}
+
+func TestRunner_WithHookBatch(t *testing.T) {
+	t.Run("Should run hooks once per batch", func(t *testing.T) {
+		var counts []int
+		r := action.New(
+			action.WithHook(func(ctx context.Context) error {
+				counts = append(counts, action.HookBatchCount(ctx))
+				return nil
+			}),
+			action.WithHookBatch(3, 0),
+		)
+		require.NoError(t, r.Start(t.Context()))
+		for range 7 {
+			action.Act(r, func() {})
+		}
+		require.Equal(t, []int{3, 3}, action.ActGet(r, func() []int {
+			return counts
+		}))
+	})
+	t.Run("Should run hooks once the interval elapsed", func(t *testing.T) {
+		var counts []int
+		r := action.New(
+			action.WithHook(func(ctx context.Context) error {
+				counts = append(counts, action.HookBatchCount(ctx))
+				return nil
+			}),
+			action.WithHookBatch(0, 20*time.Millisecond),
+		)
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() {})
+		action.Act(r, func() {})
+		time.Sleep(30 * time.Millisecond)
+		action.Act(r, func() {})
+		require.Equal(t, []int{3}, action.ActGet(r, func() []int {
+			return counts
+		}))
+	})
+	t.Run("Should report a count of 1 without batching", func(t *testing.T) {
+		var counts []int
+		r := action.New(action.WithHook(func(ctx context.Context) error {
+			counts = append(counts, action.HookBatchCount(ctx))
+			return nil
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() {})
+		require.Equal(t, []int{1}, action.ActGet(r, func() []int {
+			return counts
+		}))
+	})
+}
+
+func TestRunner_ErrorCause(t *testing.T) {
+	t.Run("Should return the cause of the context cancellation", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancelCause(t.Context())
+		require.NoError(t, r.Start(ctx))
+		cause := errors.New("leader election lost")
+		cancel(cause)
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), cause)
+	})
+	t.Run("Should return the context error without a cause", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		cancel()
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), context.Canceled)
+	})
+}
+
+func TestRunner_DoneCtx(t *testing.T) {
+	t.Run("Should be cancelled with the runner error as cause", func(t *testing.T) {
+		hookErr := errors.New("hook failed")
+		r := action.New(action.WithHook(func(ctx context.Context) error {
+			return hookErr
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.DoneCtx().Err())
+		r.Send(func() {})
+		<-r.DoneCtx().Done()
+		require.ErrorIs(t, context.Cause(r.DoneCtx()), hookErr)
+		require.ErrorIs(t, r.DoneCtx().Err(), context.Canceled)
+	})
+}
+
+func TestRunner_AddHook(t *testing.T) {
+	t.Run("Should run the hook until removed", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		label := ""
+		var seen []string
+		h := r.AddHook(func(ctx context.Context) error {
+			seen = append(seen, label)
+			return nil
+		})
+		action.Act(r, func() { label = "first" })
+		h.Remove()
+		action.Act(r, func() { label = "second" })
+		require.Equal(t, []string{"first"}, action.ActGet(r, func() []string {
+			return slices.DeleteFunc(seen, func(s string) bool { return s == "" })
+		}))
+	})
+	t.Run("Should take effect ahead of the queued actions", func(t *testing.T) {
+		r := action.New(action.WithChanSize(10))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		for range 5 {
+			action.Tell(r, func() {})
+		}
+		calls := 0
+		added := make(chan struct{})
+		go func() {
+			defer close(added)
+			r.AddHook(func(ctx context.Context) error {
+				calls++
+				return nil
+			})
+		}()
+		// Let AddHook queue its command behind the blocking action.
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		<-added
+		// The hook ran after every queued action, not yet after this one.
+		require.Equal(t, 5, action.ActGet(r, func() int { return calls }))
+	})
+	t.Run("Should stop the runner if the hook errors", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		hookErr := errors.New("error")
+		r.AddHook(func(ctx context.Context) error {
+			return hookErr
+		})
+		action.Tell(r, func() {})
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), hookErr)
+	})
+	t.Run("Should disable and restore the inline fast path", func(t *testing.T) {
+		onCaller := func() bool {
+			buf := make([]byte, 4096)
+			return !strings.Contains(string(buf[:runtime.Stack(buf, false)]), "(*Runner).start")
+		}
+		r := action.New(action.WithInlineExecution())
+		require.NoError(t, r.Start(t.Context()))
+		h := r.AddHook(func(ctx context.Context) error {
+			return nil
+		})
+		require.False(t, action.ActGet(r, onCaller))
+		h.Remove()
+		require.True(t, action.ActGet(r, onCaller))
+	})
+}
+
+func TestNewChecked(t *testing.T) {
+	hook := func(ctx context.Context) error {
+		return nil
+	}
+	tt := []struct {
+		title       string
+		opts        []func(*action.Runner)
+		expectedErr require.ErrorAssertionFunc
+	}{
+		{
+			title:       "Should accept valid options",
+			opts:        []func(*action.Runner){action.WithChanSize(10), action.WithHook(hook)},
+			expectedErr: require.NoError,
+		},
+		{
+			title:       "Should reject a zero chan size",
+			opts:        []func(*action.Runner){action.WithChanSize(0)},
+			expectedErr: require.Error,
+		},
+		{
+			title:       "Should reject a negative chan size",
+			opts:        []func(*action.Runner){action.WithChanSize(-1)},
+			expectedErr: require.Error,
+		},
+		{
+			title:       "Should reject a nil hook",
+			opts:        []func(*action.Runner){action.WithHook(nil)},
+			expectedErr: require.Error,
+		},
+		{
+			title:       "Should reject a negative timeout",
+			opts:        []func(*action.Runner){action.WithActionTimeout(-time.Second)},
+			expectedErr: require.Error,
+		},
+		{
+			title:       "Should reject inline execution with hooks",
+			opts:        []func(*action.Runner){action.WithInlineExecution(), action.WithHook(hook)},
+			expectedErr: require.Error,
+		},
+		{
+			title:       "Should reject hook batches without hooks",
+			opts:        []func(*action.Runner){action.WithHookBatch(10, 0)},
+			expectedErr: require.Error,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.title, func(t *testing.T) {
+			r, err := action.NewChecked(tc.opts...)
+			tc.expectedErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, action.ErrInvalidOption)
+				require.Nil(t, r)
+				return
+			}
+			require.NotNil(t, r)
+		})
+	}
+}
+
+func TestRunner_OnCleanup(t *testing.T) {
+	t.Run("Should run cleanups once in reverse order before Done", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		var order []int
+		r.OnCleanup(func() { order = append(order, 1) })
+		action.Act(r, func() {
+			r.OnCleanup(func() { order = append(order, 2) })
+		})
+		cancel()
+		<-r.Done()
+		require.Equal(t, []int{2, 1}, order)
+	})
+	t.Run("Should run right away once stopped", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		cancel()
+		<-r.Done()
+		called := false
+		r.OnCleanup(func() { called = true })
+		require.True(t, called)
+	})
+}
+
+func TestRunner_Remaining(t *testing.T) {
+	t.Run("Should yield the actions left in the queue", func(t *testing.T) {
+		r := action.New(action.WithChanSize(10))
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		var got []int
+		for i := range 3 {
+			r.Send(func() { got = append(got, i) })
+		}
+		require.Empty(t, slices.Collect(r.Remaining()))
+		cancel()
+		close(release)
+		<-r.Done()
+		for a := range r.Remaining() {
+			a()
+		}
+		require.Equal(t, []int{0, 1, 2}, got)
+		require.Empty(t, slices.Collect(r.Remaining()))
+	})
+}
+
+func TestRunner_Close(t *testing.T) {
+	t.Run("Should stop the runner and wait for it", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), action.ErrClosed)
+		require.NoError(t, r.Close())
+	})
+	t.Run("Should stop a parked runner", func(t *testing.T) {
+		r := action.New(action.WithIdleTimeout(time.Millisecond))
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		require.ErrorIs(t, r.Error(), action.ErrClosed)
+	})
+	t.Run("Should do nothing when not started", func(t *testing.T) {
+		require.NoError(t, action.New().Close())
+	})
+	t.Run("Should give up after CloseTimeout", func(t *testing.T) {
+		defer func(d time.Duration) { action.CloseTimeout = d }(action.CloseTimeout)
+		action.CloseTimeout = 10 * time.Millisecond
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		require.ErrorIs(t, r.Close(), context.DeadlineExceeded)
+		close(release)
+		<-r.Done()
+	})
+}
+
+func TestWithReplyPriority(t *testing.T) {
+	t.Run("Should size the queue after WithChanSize whatever the order", func(t *testing.T) {
+		r := action.New(action.WithReplyPriority(0), action.WithChanSize(8))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				action.Act(r, func() {})
+			}()
+		}
+		require.Eventually(t, func() bool { return r.Len() == 8 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+	})
+	t.Run("Should run awaited actions before the backlog", func(t *testing.T) {
+		r := action.New(action.WithChanSize(10), action.WithReplyPriority(0))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		var order []string
+		for range 3 {
+			action.Tell(r, func() { order = append(order, "tell") })
+		}
+		read := make(chan struct{})
+		go func() {
+			defer close(read)
+			action.Act(r, func() { order = append(order, "act") })
+		}()
+		// Wait for Act to be blocked on the reply, its action being queued.
+		require.Eventually(t, func() bool {
+			buf := make([]byte, 1<<16)
+			for _, g := range strings.Split(string(buf[:runtime.Stack(buf, true)]), "\n\n") {
+				if strings.Contains(g, "[select") && strings.Contains(g, "action.Act(") {
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+		close(release)
+		<-read
+		require.Equal(t, []string{"act", "tell", "tell", "tell"}, action.ActGet(r, func() []string { return order }))
+	})
+	t.Run("Should keep the order without the option", func(t *testing.T) {
+		r := action.New(action.WithChanSize(10))
+		require.NoError(t, r.Start(t.Context()))
+		var order []string
+		action.Tell(r, func() { order = append(order, "tell") })
+		action.Act(r, func() { order = append(order, "act") })
+		require.Equal(t, []string{"tell", "act"}, action.ActGet(r, func() []string { return order }))
+	})
+	t.Run("Should reject a negative size", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithReplyPriority(-1))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestRunner_Subscribe(t *testing.T) {
+	t.Run("Should deliver the error to every subscriber once", func(t *testing.T) {
+		r := action.New()
+		ctx, cancel := context.WithCancelCause(t.Context())
+		require.NoError(t, r.Start(ctx))
+		subs := []<-chan error{r.Subscribe(), r.Subscribe(), r.Subscribe()}
+		cause := errors.New("shutdown")
+		cancel(cause)
+		for _, c := range subs {
+			require.ErrorIs(t, <-c, cause)
+			_, ok := <-c
+			require.False(t, ok)
+		}
+	})
+	t.Run("Should deliver to late subscribers", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		require.ErrorIs(t, <-r.Subscribe(), action.ErrClosed)
+	})
+}
+
+func TestRunner_Pressure(t *testing.T) {
+	t.Run("Should follow the queue fill ratio", func(t *testing.T) {
+		var alerts []bool
+		r := action.New(action.WithChanSize(4), action.WithPressureAlert(0.5, 0.1, func(high bool) {
+			alerts = append(alerts, high)
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		require.Zero(t, r.Pressure())
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		for range 4 {
+			action.Tell(r, func() {})
+		}
+		require.Eventually(t, func() bool {
+			return r.Pressure() > 0.9
+		}, 2*time.Second, 10*time.Millisecond)
+		close(release)
+		require.Eventually(t, func() bool {
+			return r.Pressure() < 0.05
+		}, 2*time.Second, 10*time.Millisecond)
+		action.Act(r, func() {})
+		require.Equal(t, []bool{true, false}, action.ActGet(r, func() []bool { return alerts }))
+	})
+	t.Run("Should reject inconsistent thresholds", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithPressureAlert(0.2, 0.5, func(bool) {}))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestWithTick(t *testing.T) {
+	t.Run("Should call the tick function on the runner", func(t *testing.T) {
+		ticks := 0
+		r := action.New(action.WithTick(time.Millisecond, func(context.Context) error {
+			ticks++
+			return nil
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		require.Eventually(t, func() bool {
+			return action.ActGet(r, func() int { return ticks }) >= 3
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Should stop the runner when the tick errors", func(t *testing.T) {
+		tickErr := errors.New("tick")
+		r := action.New(action.WithTick(time.Millisecond, func(context.Context) error {
+			return tickErr
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), tickErr)
+	})
+	t.Run("Should reject a non-positive interval", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithTick(0, func(context.Context) error { return nil }))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestWithMaxActionsPerTurn(t *testing.T) {
+	t.Run("Should let a tick in during a flood of actions", func(t *testing.T) {
+		ran := 0
+		firstTick := -1
+		r := action.New(
+			action.WithReplyPriority(100),
+			action.WithMaxActionsPerTurn(5),
+			action.WithTick(time.Millisecond, func(context.Context) error {
+				if ran > 0 && firstTick < 0 {
+					firstTick = ran
+				}
+				return nil
+			}),
+		)
+		started, release := make(chan struct{}), make(chan struct{})
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				action.Act(r, func() { ran++ })
+			}()
+		}
+		// Wait for every Act to be blocked on its reply, its action queued.
+		require.Eventually(t, func() bool {
+			buf := make([]byte, 1<<20)
+			n := 0
+			for _, g := range strings.Split(string(buf[:runtime.Stack(buf, true)]), "\n\n") {
+				if strings.Contains(g, "[select") && strings.Contains(g, "action.Act(") {
+					n++
+				}
+			}
+			return n == 50
+		}, time.Second, time.Millisecond)
+		// Awaited actions are taken ahead of the tick, which only gets in when
+		// the runner yields. The blocking action took the first slot.
+		time.Sleep(5 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		require.Equal(t, 4, action.ActGet(r, func() int { return firstTick }))
+	})
+	t.Run("Should reject a non-positive budget", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithMaxActionsPerTurn(0))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestWithWarmup(t *testing.T) {
+	t.Run("Should run before the first action", func(t *testing.T) {
+		var cache map[string]int
+		release := make(chan struct{})
+		r := action.New(action.WithInlineExecution(), action.WithWarmup(func(context.Context) error {
+			<-release
+			cache = map[string]int{"a": 1}
+			return nil
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		go close(release)
+		require.Equal(t, 1, action.ActGet(r, func() int { return cache["a"] }))
+	})
+	t.Run("Should stop the runner when it fails", func(t *testing.T) {
+		errWarmup := errors.New("warmup")
+		r := action.New(action.WithWarmup(func(context.Context) error {
+			return errWarmup
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errWarmup)
+	})
+}
+
+func TestRunner_StopOn(t *testing.T) {
+	t.Run("Should stop when any context is done", func(t *testing.T) {
+		before, cancelBefore := context.WithCancelCause(t.Context())
+		defer cancelBefore(nil)
+		after, cancelAfter := context.WithCancelCause(t.Context())
+		r := action.New()
+		r.StopOn(before)
+		require.NoError(t, r.Start(t.Context()))
+		r.StopOn(after)
+		errLost := errors.New("leadership lost")
+		cancelAfter(errLost)
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errLost)
+	})
+	t.Run("Should stop on a context registered before Start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		r := action.New()
+		r.StopOn(ctx)
+		require.NoError(t, r.Start(t.Context()))
+		cancel()
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), context.Canceled)
+	})
+	t.Run("Should stay tied after a Reset", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(t.Context())
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		r.StopOn(ctx)
+		require.NoError(t, r.Close())
+		require.NoError(t, r.Reset())
+		require.NoError(t, r.Start(t.Context()))
+		errLost := errors.New("leadership lost")
+		cancel(errLost)
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errLost)
+	})
+}
+
+func TestRunner_TrySend(t *testing.T) {
+	t.Run("Should fail when the queue is full", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		ran := 0
+		require.True(t, r.TrySend(func() { ran++ }))
+		require.False(t, r.TrySend(func() { ran++ }))
+		require.False(t, action.TryAct(r, func() { ran++ }))
+		close(release)
+		require.Equal(t, 1, action.ActGet(r, func() int { return ran }))
+		require.True(t, action.TryAct(r, func() { ran++ }))
+		require.Equal(t, 2, action.ActGet(r, func() int { return ran }))
+	})
+}
+
+func TestRunner_Reset(t *testing.T) {
+	t.Run("Should restart a stopped runner", func(t *testing.T) {
+		r := action.New()
+		a := action.NewRWActable(r, 1)
+		require.NoError(t, r.Start(t.Context()))
+		a.Set(2)
+		cleanups := 0
+		r.OnCleanup(func() { cleanups++ })
+		r.Stop()
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), action.ErrClosed)
+		require.Equal(t, 1, cleanups)
+		require.NoError(t, r.Reset())
+		require.NoError(t, r.Error())
+		require.NoError(t, r.Start(t.Context()))
+		require.Equal(t, 2, a.Get())
+		require.NoError(t, r.Close())
+		require.Equal(t, 1, cleanups)
+	})
+	t.Run("Should refuse a running runner", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.ErrorIs(t, r.Reset(), action.ErrNotStopped)
+	})
+	t.Run("Should do nothing when not started", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Reset())
+		r.Stop()
+		require.NoError(t, r.Start(t.Context()))
+	})
+	t.Run("Should restart a named runner right away", func(t *testing.T) {
+		r := action.New(action.WithName("reset-named"))
+		for range 200 {
+			require.NoError(t, r.Start(t.Context()))
+			require.NoError(t, r.Close())
+			require.NoError(t, r.Reset())
+		}
+		_, ok := action.Lookup("reset-named")
+		require.False(t, ok)
+	})
+}
+
+func TestRunner_SendErr(t *testing.T) {
+	t.Run("Should enqueue the action", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := make(chan struct{})
+		require.NoError(t, r.SendErr(func() { close(ran) }))
+		<-ran
+	})
+	t.Run("Should fail instead of panicking once stopped", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		require.NoError(t, r.Close())
+		err := r.SendErr(func() {})
+		require.ErrorIs(t, err, action.ErrStopped)
+		require.ErrorIs(t, err, action.ErrClosed)
+	})
+}
@@ -0,0 +1,133 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Dispatch selects the runner of a Pool receiving the next action.
+type Dispatch int
+
+const (
+	// RoundRobin sends actions to each runner in turn.
+	RoundRobin Dispatch = iota
+	// LeastLoaded sends actions to the runner with the fewest actions
+	// queued or running.
+	LeastLoaded
+)
+
+var (
+	_ RunnersV2 = (*Pool)(nil)
+	_ io.Closer = (*Pool)(nil)
+)
+
+// Pool spreads actions over a fixed set of runners. Actions sent to a pool
+// are not serialized with each other, so it only suits workloads that do
+// not share state, or that share it through their own synchronization.
+type Pool struct {
+	runners  []*Runner
+	loads    []atomic.Int64
+	dispatch Dispatch
+	next     atomic.Uint64
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+}
+
+// NewPool returns a pool of n runners created with opts. n is at least 1.
+// The runners would share a name given with WithName, so Start refuses it
+// for more than one runner.
+func NewPool(n int, dispatch Dispatch, opts ...func(*Runner)) *Pool {
+	p := &Pool{
+		runners:  make([]*Runner, max(n, 1)),
+		dispatch: dispatch,
+	}
+	if dispatch == LeastLoaded {
+		p.loads = make([]atomic.Int64, len(p.runners))
+	}
+	for i := range p.runners {
+		p.runners[i] = New(opts...)
+	}
+	return p
+}
+
+// Start starts every runner of the pool. The pool context is cancelled as
+// soon as one of them stops.
+func (p *Pool) Start(ctx context.Context) error {
+	if name := p.runners[0].name; name != "" && len(p.runners) > 1 {
+		return fmt.Errorf("%w: name %q shared by %d pooled runners", ErrInvalidOption, name, len(p.runners))
+	}
+	ctx, p.cancel = context.WithCancelCause(ctx)
+	p.ctx = ctx
+	var errs []error
+	for _, r := range p.runners {
+		if err := r.Start(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		context.AfterFunc(r.Ctx(), func() {
+			p.cancel(context.Cause(r.Ctx()))
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// Send enqueues the action on the runner chosen by the dispatch policy.
+func (p *Pool) Send(a Action) {
+	if p.dispatch != LeastLoaded {
+		p.runners[(p.next.Add(1)-1)%uint64(len(p.runners))].Send(a)
+		return
+	}
+	i := p.leastLoaded()
+	p.loads[i].Add(1)
+	p.runners[i].Send(func() {
+		defer p.loads[i].Add(-1)
+		a()
+	})
+}
+
+// Use adds a middleware to every runner of the pool, see Runner.Use.
+func (p *Pool) Use(m Middleware) {
+	for _, r := range p.runners {
+		r.Use(m)
+	}
+}
+
+// Observe adds an observer to every runner of the pool, see
+// Runner.Observe. It is called concurrently by the runners.
+func (p *Pool) Observe(o Observer) {
+	for _, r := range p.runners {
+		r.Observe(o)
+	}
+}
+
+// leastLoaded returns the index of the runner with the fewest actions.
+func (p *Pool) leastLoaded() int {
+	best := 0
+	for i := 1; i < len(p.loads); i++ {
+		if p.loads[i].Load() < p.loads[best].Load() {
+			best = i
+		}
+	}
+	return best
+}
+
+// Ctx returns the pool context, cancelled once any runner stops.
+func (p *Pool) Ctx() context.Context {
+	return p.ctx
+}
+
+// Close closes every runner of the pool, see Runner.Close.
+func (p *Pool) Close() error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel(ErrClosed)
+	var errs []error
+	for _, r := range p.runners {
+		errs = append(errs, r.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	t.Run("Should refuse a name shared by its runners", func(t *testing.T) {
+		p := action.NewPool(2, action.RoundRobin, action.WithName("pool-named"))
+		require.ErrorIs(t, p.Start(t.Context()), action.ErrInvalidOption)
+		_, ok := action.Lookup("pool-named")
+		require.False(t, ok)
+	})
+	t.Run("Should run the actions across the runners", func(t *testing.T) {
+		p := action.NewPool(4, action.RoundRobin)
+		require.NoError(t, p.Start(t.Context()))
+		defer p.Close()
+		var wg sync.WaitGroup
+		release := make(chan struct{})
+		for range 4 {
+			wg.Add(1)
+			p.Send(func() {
+				wg.Done()
+				<-release
+			})
+		}
+		wg.Wait()
+		close(release)
+	})
+	t.Run("Should work with the Act helpers", func(t *testing.T) {
+		p := action.NewPool(2, action.LeastLoaded)
+		require.NoError(t, p.Start(t.Context()))
+		defer p.Close()
+		require.Equal(t, 42, action.ActGet(p, func() int { return 42 }))
+	})
+	t.Run("Should send to the least loaded runner", func(t *testing.T) {
+		p := action.NewPool(2, action.LeastLoaded, action.WithChanSize(4))
+		require.NoError(t, p.Start(t.Context()))
+		defer p.Close()
+		started, release := make(chan struct{}), make(chan struct{})
+		p.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		p.Send(func() {})
+		p.Send(func() {})
+		done := make(chan struct{})
+		p.Send(func() { close(done) })
+		<-done
+		close(release)
+	})
+	t.Run("Should cancel its context when a runner stops", func(t *testing.T) {
+		p := action.NewPool(2, action.RoundRobin)
+		require.NoError(t, p.Start(t.Context()))
+		require.NoError(t, p.Close())
+		<-p.Ctx().Done()
+	})
+	t.Run("Should wrap and observe the actions of every runner", func(t *testing.T) {
+		p := action.NewPool(3, action.RoundRobin)
+		var wrapped, observed atomic.Int32
+		p.Use(func(next action.Action) action.Action {
+			return func() {
+				wrapped.Add(1)
+				next()
+			}
+		})
+		p.Observe(func(action.ActionEvent) { observed.Add(1) })
+		require.NoError(t, p.Start(t.Context()))
+		defer p.Close()
+		for range 3 {
+			action.Act(p, func() {})
+		}
+		require.Equal(t, int32(3), wrapped.Load())
+		require.Eventually(t, func() bool { return observed.Load() == 3 }, time.Second, time.Millisecond)
+	})
+}
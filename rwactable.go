@@ -0,0 +1,436 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RWActable holds a value whose writes are serialized by a runner while reads
+// are served concurrently from the last published snapshot, without going
+// through the runner.
+//
+// Snapshots are shared between readers: values holding references (slices,
+// maps, pointers) must be copied, not modified in place, by Update.
+type RWActable[T any] struct {
+	r     Runners
+	value atomic.Pointer[T]
+	// seq is odd while a value is being published, its half is the version.
+	seq atomic.Uint64
+
+	// Write coalescing, see WithWriteCoalescing.
+	window    time.Duration
+	pendingMu sync.Mutex
+	pending   *T
+
+	// Backing store, see WithStore.
+	load   Loader[T]
+	save   Saver[T]
+	onErr  func(error)
+	loaded atomic.Bool
+
+	// See WithValidation.
+	validate bool
+
+	// Change subscriptions, only touched on the runner, see OnChange and
+	// Watch.
+	changes  []*change[T]
+	watchers map[*subscriber[T]]struct{}
+}
+
+// change is an OnChange callback, dropped on the next publish once
+// cancelled.
+type change[T any] struct {
+	f         func(old, new T)
+	cancelled atomic.Bool
+}
+
+// Loader reads a value from a backing store.
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// Saver writes a value to a backing store.
+type Saver[T any] func(ctx context.Context, v T) error
+
+// NewRWActable returns an RWActable holding v, written through r.
+func NewRWActable[T any](r Runners, v T, opts ...func(*RWActable[T])) *RWActable[T] {
+	a := &RWActable[T]{r: r}
+	a.value.Store(&v)
+	a.loaded.Store(true)
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// WithWriteCoalescing makes Set return right away and publish only the last
+// value set within window, with a single action on the runner. It cuts the
+// runner load of chatty producers such as sliders or sensor streams. Update
+// and ApplyJSONPatch see the values set before them. Values whose window
+// ends while the runner is not started or stopped are dropped. If 0, will be
+// ignored.
+func WithWriteCoalescing[T any](window time.Duration) func(*RWActable[T]) {
+	return func(a *RWActable[T]) {
+		a.window = max(window, 0)
+	}
+}
+
+// WithStore backs the value by a store. The first Get loads it with load,
+// unless a write came first, and writes are saved with save after being
+// published, without the writer waiting for it. Both run on the runner, so
+// the store sees loads and saves in order. Their errors are given to onErr,
+// which may be nil; a failed load is retried by the next Get. Either load
+// or save may be nil.
+func WithStore[T any](load Loader[T], save Saver[T], onErr func(error)) func(*RWActable[T]) {
+	return func(a *RWActable[T]) {
+		a.load = load
+		a.save = save
+		a.onErr = onErr
+		a.loaded.Store(load == nil)
+	}
+}
+
+// WithValidation makes the writes check the new value with Validate, so
+// the value never holds an invalid state. Set drops invalid values, SetErr
+// and the other writes report them.
+func WithValidation[T any]() func(*RWActable[T]) {
+	return func(a *RWActable[T]) {
+		a.validate = true
+	}
+}
+
+func (a *RWActable[T]) check(v *T) error {
+	if !a.validate {
+		return nil
+	}
+	return Validate(v)
+}
+
+// Get returns the last published value, loading it first with WithStore.
+func (a *RWActable[T]) Get() T {
+	if !a.loaded.Load() {
+		Act(a.r, a.loadCold)
+	}
+	return *a.value.Load()
+}
+
+// GetVersioned returns the last published value with its version, which
+// increases with every write. See ChangedSince.
+func (a *RWActable[T]) GetVersioned() (T, uint64) {
+	// Load the value first with WithStore.
+	a.Get()
+	for {
+		s := a.seq.Load()
+		if s%2 == 1 {
+			runtime.Gosched()
+			continue
+		}
+		v := a.value.Load()
+		if a.seq.Load() == s {
+			return *v, s / 2
+		}
+	}
+}
+
+// ChangedSince reports whether a value was published since the version
+// returned by GetVersioned, without copying the value.
+func (a *RWActable[T]) ChangedSince(version uint64) bool {
+	for {
+		if s := a.seq.Load(); s%2 == 0 {
+			return s/2 != version
+		}
+		runtime.Gosched()
+	}
+}
+
+// loadCold loads the value from the store if nothing was published yet. It
+// runs on the runner.
+func (a *RWActable[T]) loadCold() {
+	if a.loaded.Load() {
+		return
+	}
+	v, err := a.load(a.r.Ctx())
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	a.publish(&v)
+}
+
+// publish makes v the current value and notifies the change
+// subscriptions. It runs on the runner.
+func (a *RWActable[T]) publish(v *T) {
+	old := a.value.Load()
+	a.seq.Add(1)
+	a.value.Store(v)
+	a.seq.Add(1)
+	a.loaded.Store(true)
+	a.notify(*old, *v)
+}
+
+// notify calls the OnChange callbacks then feeds the Watch channels. It
+// runs on the runner.
+func (a *RWActable[T]) notify(old, v T) {
+	changes := a.changes[:0]
+	for _, c := range a.changes {
+		if c.cancelled.Load() {
+			continue
+		}
+		c.f(old, v)
+		changes = append(changes, c)
+	}
+	clear(a.changes[len(changes):])
+	a.changes = changes
+	for s := range a.watchers {
+		if !s.deliver(v) {
+			delete(a.watchers, s)
+		}
+	}
+}
+
+// OnChange calls f with the previous and the new value after every write,
+// including the load of WithStore, until cancel is called. f is called on
+// the runner, in publish order, so it must not wait on the runner.
+func (a *RWActable[T]) OnChange(f func(old, new T)) (cancel func()) {
+	c := &change[T]{f: f}
+	Act(a.r, func() {
+		a.changes = append(a.changes, c)
+	})
+	return func() {
+		c.cancelled.Store(true)
+	}
+}
+
+// Watch returns a channel receiving the values published from now on, in
+// order, buffered as set by opts, see Bus.Subscribe. The channel is closed
+// once ctx is done.
+func (a *RWActable[T]) Watch(ctx context.Context, opts ...func(*SubscribeConfig)) <-chan T {
+	s, ok := newSubscriber[T](ctx, opts)
+	if !ok {
+		return s.c
+	}
+	Act(a.r, func() {
+		if a.watchers == nil {
+			a.watchers = make(map[*subscriber[T]]struct{})
+		}
+		a.watchers[s] = struct{}{}
+	})
+	context.AfterFunc(ctx, s.close)
+	return s.c
+}
+
+// persist enqueues the save of the current value, after the write that
+// published it.
+func (a *RWActable[T]) persist() {
+	if a.save == nil {
+		return
+	}
+	// Called from timers too, it must not panic on a stopped runner.
+	err := TellErr(a.r, func() {
+		if err := a.save(a.r.Ctx(), *a.value.Load()); err != nil {
+			a.fail(err)
+		}
+	})
+	if err != nil {
+		a.fail(err)
+	}
+}
+
+func (a *RWActable[T]) fail(err error) {
+	if a.onErr != nil {
+		a.onErr(err)
+	}
+}
+
+// Set publishes v once the writes enqueued before it have been applied.
+// With WithWriteCoalescing, it returns before v is published.
+func (a *RWActable[T]) Set(v T) {
+	_ = a.SetErr(v)
+}
+
+// SetErr is like Set, but returns the error of an invalid value, see
+// WithValidation.
+func (a *RWActable[T]) SetErr(v T) error {
+	if err := a.check(&v); err != nil {
+		return err
+	}
+	if a.window > 0 {
+		a.pendingMu.Lock()
+		scheduled := a.pending != nil
+		a.pending = &v
+		a.pendingMu.Unlock()
+		if !scheduled {
+			time.AfterFunc(a.window, a.flush)
+		}
+		return nil
+	}
+	Act(a.r, func() {
+		a.publish(&v)
+	})
+	a.persist()
+	return nil
+}
+
+// flush publishes the coalesced value on the runner. If the runner is not
+// started or stopped, the value is dropped so the next Set schedules a flush.
+func (a *RWActable[T]) flush() {
+	if a.r.Ctx() == nil || TellErr(a.r, a.applyPending) != nil {
+		a.pendingMu.Lock()
+		a.pending = nil
+		a.pendingMu.Unlock()
+		return
+	}
+	a.persist()
+}
+
+// applyPending publishes the coalesced value, if any. It runs on the runner.
+func (a *RWActable[T]) applyPending() {
+	if a.window == 0 {
+		return
+	}
+	a.pendingMu.Lock()
+	v := a.pending
+	a.pending = nil
+	a.pendingMu.Unlock()
+	if v != nil {
+		a.publish(v)
+	}
+}
+
+// Update publishes the value returned by f, called on the runner with the
+// current value, and returns it. With WithValidation, an invalid value is
+// dropped and the current one returned.
+func (a *RWActable[T]) Update(f func(T) T) T {
+	v := ActGet(a.r, func() T {
+		a.applyPending()
+		v := f(*a.value.Load())
+		if a.check(&v) != nil {
+			return *a.value.Load()
+		}
+		a.publish(&v)
+		return v
+	})
+	a.persist()
+	return v
+}
+
+// Swap publishes v and returns the value it replaced, in a single action on
+// the runner. With WithValidation, an invalid value is dropped and the
+// current one returned.
+func (a *RWActable[T]) Swap(v T) (old T) {
+	swapped := false
+	old = ActGet(a.r, func() T {
+		a.applyPending()
+		old := *a.value.Load()
+		if a.check(&v) != nil {
+			return old
+		}
+		a.publish(&v)
+		swapped = true
+		return old
+	})
+	if swapped {
+		a.persist()
+	}
+	return old
+}
+
+// CompareAndSwap publishes v if the value of a is old, in a single action on
+// its runner, and reports whether it did. With WithValidation, an invalid
+// value is never swapped.
+func CompareAndSwap[T comparable](a *RWActable[T], old, v T) bool {
+	swapped := ActGet(a.r, func() bool {
+		a.applyPending()
+		if *a.value.Load() != old || a.check(&v) != nil {
+			return false
+		}
+		a.publish(&v)
+		return true
+	})
+	if swapped {
+		a.persist()
+	}
+	return swapped
+}
+
+// ApplyJSONPatch applies an RFC 7386 JSON merge patch to the value on the
+// runner and publishes the result. The value goes through encoding/json, so
+// only its exported fields survive. On error, the value is left unchanged.
+func (a *RWActable[T]) ApplyJSONPatch(patch []byte) error {
+	var p any
+	if err := decodeJSON(patch, &p); err != nil {
+		return err
+	}
+	err := ActErr(a.r, func() error {
+		a.applyPending()
+		current, err := json.Marshal(a.value.Load())
+		if err != nil {
+			return err
+		}
+		var doc any
+		if err := decodeJSON(current, &doc); err != nil {
+			return err
+		}
+		merged, err := json.Marshal(mergePatch(doc, p))
+		if err != nil {
+			return err
+		}
+		var v T
+		if err := json.Unmarshal(merged, &v); err != nil {
+			return err
+		}
+		if err := a.check(&v); err != nil {
+			return err
+		}
+		a.publish(&v)
+		return nil
+	})
+	if err == nil {
+		a.persist()
+	}
+	return err
+}
+
+// MarshalJSON encodes the last published value.
+func (a *RWActable[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Get())
+}
+
+// UnmarshalJSON decodes data and sets it as the value, see SetErr.
+func (a *RWActable[T]) UnmarshalJSON(data []byte) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return a.SetErr(v)
+}
+
+// decodeJSON decodes numbers as json.Number so large integers survive.
+func decodeJSON(data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// mergePatch returns target with the merge patch applied, see RFC 7386.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
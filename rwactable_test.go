@@ -0,0 +1,379 @@
+package action_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRWActable(t *testing.T) {
+	t.Run("Should return the initial value", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, "hello")
+		require.Equal(t, "hello", a.Get())
+	})
+	t.Run("Should publish set values", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, "hello")
+		a.Set("world")
+		require.Equal(t, "world", a.Get())
+	})
+	t.Run("Should serialize concurrent updates", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		var wg sync.WaitGroup
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				a.Update(func(v int) int {
+					return v + 1
+				})
+				_ = a.Get()
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, 100, a.Get())
+	})
+}
+
+func TestRWActable_ApplyJSONPatch(t *testing.T) {
+	type limits struct {
+		Max int `json:"max"`
+		Min int `json:"min,omitempty"`
+	}
+	type config struct {
+		Name   string            `json:"name"`
+		Tags   map[string]string `json:"tags,omitempty"`
+		Limits limits            `json:"limits"`
+	}
+	initial := config{
+		Name:   "svc",
+		Tags:   map[string]string{"env": "dev", "team": "core"},
+		Limits: limits{Max: 10, Min: 1},
+	}
+	tt := []struct {
+		title    string
+		patch    string
+		expected config
+		err      require.ErrorAssertionFunc
+	}{
+		{
+			title: "Should merge nested objects and remove null members",
+			patch: `{"tags":{"env":"prod","team":null},"limits":{"max":20}}`,
+			expected: config{
+				Name:   "svc",
+				Tags:   map[string]string{"env": "prod"},
+				Limits: limits{Max: 20, Min: 1},
+			},
+			err: require.NoError,
+		},
+		{
+			title: "Should replace non-object members",
+			patch: `{"name":"other"}`,
+			expected: config{
+				Name:   "other",
+				Tags:   map[string]string{"env": "dev", "team": "core"},
+				Limits: limits{Max: 10, Min: 1},
+			},
+			err: require.NoError,
+		},
+		{
+			title:    "Should leave the value unchanged on invalid patches",
+			patch:    `{"limits":{"max":"many"}}`,
+			expected: initial,
+			err:      require.Error,
+		},
+		{
+			title:    "Should reject malformed JSON",
+			patch:    `{`,
+			expected: initial,
+			err:      require.Error,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.title, func(t *testing.T) {
+			r := action.New()
+			require.NoError(t, r.Start(t.Context()))
+			a := action.NewRWActable(r, initial)
+			tc.err(t, a.ApplyJSONPatch([]byte(tc.patch)))
+			require.Equal(t, tc.expected, a.Get())
+		})
+	}
+}
+
+func TestRWActable_WithWriteCoalescing(t *testing.T) {
+	t.Run("Should publish the last value with few actions", func(t *testing.T) {
+		var actions atomic.Int64
+		r := action.New(action.WithHook(func(context.Context) error {
+			actions.Add(1)
+			return nil
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0, action.WithWriteCoalescing[int](10*time.Millisecond))
+		for i := range 100 {
+			a.Set(i + 1)
+		}
+		require.Eventually(t, func() bool {
+			return a.Get() == 100
+		}, time.Second, time.Millisecond)
+		require.Less(t, actions.Load(), int64(10))
+	})
+	t.Run("Should apply pending values before an update", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0, action.WithWriteCoalescing[int](time.Hour))
+		a.Set(41)
+		require.Zero(t, a.Get())
+		require.Equal(t, 42, a.Update(func(v int) int { return v + 1 }))
+	})
+	t.Run("Should publish values set after a flush before Start", func(t *testing.T) {
+		r := action.New()
+		a := action.NewRWActable(r, 0, action.WithWriteCoalescing[int](time.Millisecond))
+		a.Set(1)
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, r.Start(t.Context()))
+		for i := 2; i <= 9; i++ {
+			a.Set(i)
+		}
+		require.Eventually(t, func() bool {
+			return a.Get() == 9
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Should drop values flushed after the runner stopped", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		saved := make(chan int, 1)
+		a := action.NewRWActable(r, 0, action.WithWriteCoalescing[int](5*time.Millisecond),
+			action.WithStore(nil, func(_ context.Context, v int) error {
+				saved <- v
+				return nil
+			}, nil))
+		a.Set(1)
+		require.NoError(t, r.Close())
+		time.Sleep(20 * time.Millisecond)
+		require.NoError(t, r.Reset())
+		require.NoError(t, r.Start(t.Context()))
+		defer r.Close()
+		a.Set(2)
+		select {
+		case v := <-saved:
+			require.Equal(t, 2, v)
+		case <-time.After(time.Second):
+			t.Fatal("value not saved")
+		}
+		require.Equal(t, 2, a.Get())
+	})
+}
+
+func TestRWActable_WithStore(t *testing.T) {
+	t.Run("Should load the value on the first Get", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		loads := 0
+		a := action.NewRWActable(r, "", action.WithStore(func(context.Context) (string, error) {
+			loads++
+			return "stored", nil
+		}, nil, nil))
+		require.Equal(t, "stored", a.Get())
+		require.Equal(t, "stored", a.Get())
+		require.Equal(t, 1, action.ActGet(r, func() int { return loads }))
+	})
+	t.Run("Should retry a failed load and report its error", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		loadErr := errors.New("load")
+		var errs []error
+		fail := true
+		a := action.NewRWActable(r, 0, action.WithStore(func(context.Context) (int, error) {
+			if fail {
+				fail = false
+				return 0, loadErr
+			}
+			return 7, nil
+		}, nil, func(err error) { errs = append(errs, err) }))
+		require.Zero(t, a.Get())
+		require.Equal(t, 7, a.Get())
+		require.Equal(t, []error{loadErr}, action.ActGet(r, func() []error { return errs }))
+	})
+	t.Run("Should save writes in order without loading", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		var saved []int
+		a := action.NewRWActable(r, 0, action.WithStore(func(context.Context) (int, error) {
+			return 0, errors.New("should not load")
+		}, func(_ context.Context, v int) error {
+			saved = append(saved, v)
+			return nil
+		}, nil))
+		a.Set(1)
+		a.Update(func(v int) int { return v + 1 })
+		require.Equal(t, 2, a.Get())
+		require.Equal(t, []int{1, 2}, action.ActGet(r, func() []int { return saved }))
+	})
+}
+
+func TestRWActable_WithValidation(t *testing.T) {
+	type config struct {
+		Workers int `json:"workers" validate:"min=1"`
+	}
+	r := action.New()
+	require.NoError(t, r.Start(t.Context()))
+	a := action.NewRWActable(r, config{Workers: 1}, action.WithValidation[config]())
+	t.Run("Should reject invalid values", func(t *testing.T) {
+		require.ErrorIs(t, a.SetErr(config{}), action.ErrInvalidValue)
+		a.Set(config{})
+		require.Equal(t, config{Workers: 1}, a.Update(func(c config) config { return config{} }))
+		require.ErrorIs(t, a.ApplyJSONPatch([]byte(`{"workers":0}`)), action.ErrInvalidValue)
+		require.ErrorIs(t, json.Unmarshal([]byte(`{"workers":-1}`), a), action.ErrInvalidValue)
+		require.Equal(t, config{Workers: 1}, a.Get())
+	})
+	t.Run("Should accept valid values", func(t *testing.T) {
+		require.NoError(t, json.Unmarshal([]byte(`{"workers":4}`), a))
+		data, err := json.Marshal(a)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"workers":4}`, string(data))
+	})
+}
+
+func TestRWActable_WithValidation_PointerReceiver(t *testing.T) {
+	t.Run("Should reject values invalid for a pointer Validate", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, pool{Size: 1}, action.WithValidation[pool]())
+		require.ErrorIs(t, a.SetErr(pool{Size: -1}), action.ErrInvalidValue)
+		require.Equal(t, pool{Size: 1}, a.Get())
+	})
+}
+
+func TestRWActable_GetVersioned(t *testing.T) {
+	t.Run("Should report changes since a version", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, "a")
+		v, version := a.GetVersioned()
+		require.Equal(t, "a", v)
+		require.False(t, a.ChangedSince(version))
+		a.Set("b")
+		require.True(t, a.ChangedSince(version))
+		v, next := a.GetVersioned()
+		require.Equal(t, "b", v)
+		require.Greater(t, next, version)
+		require.False(t, a.ChangedSince(next))
+	})
+}
+
+func TestRWActable_OnChange(t *testing.T) {
+	t.Run("Should report every change in order", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		var changes [][2]int
+		a.OnChange(func(old, v int) {
+			changes = append(changes, [2]int{old, v})
+		})
+		for i := 1; i <= 3; i++ {
+			a.Set(i)
+		}
+		a.Update(func(v int) int { return v * 10 })
+		got := action.ActGet(r, func() [][2]int { return changes })
+		require.Equal(t, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 30}}, got)
+	})
+	t.Run("Should stop reporting once cancelled", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		var calls int
+		cancel := a.OnChange(func(int, int) { calls++ })
+		a.Set(1)
+		cancel()
+		a.Set(2)
+		require.Equal(t, 1, action.ActGet(r, func() int { return calls }))
+	})
+}
+
+func TestRWActable_Watch(t *testing.T) {
+	t.Run("Should receive the published values in order", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		c := a.Watch(t.Context())
+		for i := 1; i <= 3; i++ {
+			a.Set(i)
+		}
+		for i := 1; i <= 3; i++ {
+			require.Equal(t, i, <-c)
+		}
+	})
+	t.Run("Should close the channel once ctx is done", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		ctx, cancel := context.WithCancel(t.Context())
+		c := a.Watch(ctx)
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := <-c
+			return !ok
+		}, time.Second, time.Millisecond)
+		a.Set(1)
+	})
+	t.Run("Should not block dropping the oldest values without buffer", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		a.Watch(t.Context(), action.WithBuffer(0), action.WithOverflow(action.OverflowDropOldest))
+		a.Set(1)
+		require.Equal(t, 1, a.Get())
+	})
+}
+
+func TestRWActable_Swap(t *testing.T) {
+	t.Run("Should return the replaced value", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, "hello")
+		require.Equal(t, "hello", a.Swap("world"))
+		require.Equal(t, "world", a.Get())
+	})
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	t.Run("Should swap only the expected value", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 1)
+		require.False(t, action.CompareAndSwap(a, 2, 3))
+		require.Equal(t, 1, a.Get())
+		require.True(t, action.CompareAndSwap(a, 1, 3))
+		require.Equal(t, 3, a.Get())
+	})
+	t.Run("Should let a single concurrent swap win", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		a := action.NewRWActable(r, 0)
+		var wins atomic.Int64
+		var wg sync.WaitGroup
+		for i := range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if action.CompareAndSwap(a, 0, i+1) {
+					wins.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int64(1), wins.Load())
+	})
+}
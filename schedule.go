@@ -0,0 +1,183 @@
+package action
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ScheduleAfter sends the action to the runner once d has elapsed, so it
+// runs serialized with the other actions. Calling cancel prevents it from
+// running if it has not started yet. The action is dropped if the runner
+// is not running when the time fires.
+func (r *Runner) ScheduleAfter(d time.Duration, a Action) (cancel func()) {
+	var cancelled atomic.Bool
+	t := time.AfterFunc(d, func() {
+		if r.Ctx() == nil {
+			return
+		}
+		// A stopped runner fails the send, dropping the action.
+		_ = r.SendErr(func() {
+			if !cancelled.Load() {
+				a()
+			}
+		})
+	})
+	return func() {
+		cancelled.Store(true)
+		t.Stop()
+	}
+}
+
+// ScheduleAt is like ScheduleAfter but sends the action at t.
+func (r *Runner) ScheduleAt(t time.Time, a Action) (cancel func()) {
+	return r.ScheduleAfter(time.Until(t), a)
+}
+
+// Job is a recurring action scheduled with ScheduleEvery or ScheduleCron.
+type Job struct {
+	r         *Runner
+	name      string
+	jitter    time.Duration
+	next      func(time.Time) time.Time
+	action    Action
+	mu        sync.Mutex
+	timer     *time.Timer
+	at        time.Time
+	cancelled atomic.Bool
+}
+
+// WithJobName names the job, so it can be found with Runner.Job. Names are
+// unique per runner.
+func WithJobName(name string) func(*Job) {
+	return func(j *Job) {
+		j.name = name
+	}
+}
+
+// WithJitter delays each run of the job by a random duration up to d, to
+// spread jobs of many runners sharing a schedule.
+func WithJitter(d time.Duration) func(*Job) {
+	return func(j *Job) {
+		j.jitter = max(d, 0)
+	}
+}
+
+// ScheduleEvery sends the action to the runner every interval, starting
+// one interval from now, until the job is cancelled or the runner stops.
+// Runs are skipped while the runner is not started. It returns
+// ErrNameTaken if a job of the same name is scheduled.
+func (r *Runner) ScheduleEvery(interval time.Duration, a Action, opts ...func(*Job)) (*Job, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("schedule every %s: %w", interval, ErrInvalidOption)
+	}
+	return r.schedule(func(t time.Time) time.Time { return t.Add(interval) }, a, opts)
+}
+
+// ScheduleCron is like ScheduleEvery but sends the action at the times
+// matched by a five fields cron expression, such as "*/15 9-17 * * 1-5",
+// in the local time zone. The @hourly, @daily, @weekly, @monthly and
+// @yearly descriptors are supported too.
+func (r *Runner) ScheduleCron(spec string, a Action, opts ...func(*Job)) (*Job, error) {
+	s, err := parseCron(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	return r.schedule(s.next, a, opts)
+}
+
+func (r *Runner) schedule(next func(time.Time) time.Time, a Action, opts []func(*Job)) (*Job, error) {
+	j := &Job{r: r, next: next, action: a}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.name != "" {
+		r.jobsMu.Lock()
+		if _, ok := r.jobs[j.name]; ok {
+			r.jobsMu.Unlock()
+			return nil, ErrNameTaken
+		}
+		if r.jobs == nil {
+			r.jobs = make(map[string]*Job)
+		}
+		r.jobs[j.name] = j
+		r.jobsMu.Unlock()
+	}
+	j.arm(time.Now())
+	return j, nil
+}
+
+// Job returns the job scheduled under name, if any.
+func (r *Runner) Job(name string) (*Job, bool) {
+	r.jobsMu.Lock()
+	defer r.jobsMu.Unlock()
+	j, ok := r.jobs[name]
+	return j, ok
+}
+
+// arm sets the timer of the next run after now.
+func (j *Job) arm(now time.Time) {
+	at := j.next(now)
+	if at.IsZero() {
+		j.Cancel()
+		return
+	}
+	d := time.Until(at)
+	if j.jitter > 0 {
+		d += rand.N(j.jitter)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.cancelled.Load() {
+		j.at = at
+		j.timer = time.AfterFunc(d, j.fire)
+	}
+}
+
+func (j *Job) fire() {
+	if j.r.Ctx() != nil {
+		err := j.r.SendErr(func() {
+			if !j.cancelled.Load() {
+				j.action()
+			}
+		})
+		if err != nil {
+			j.Cancel()
+			return
+		}
+	}
+	j.arm(time.Now())
+}
+
+// Name returns the name of the job, empty if it has none.
+func (j *Job) Name() string {
+	return j.name
+}
+
+// Next returns when the job is scheduled to run next, before jitter.
+func (j *Job) Next() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.at
+}
+
+// Cancel stops the job. A run already sent but not started is skipped.
+func (j *Job) Cancel() {
+	if j.cancelled.Swap(true) {
+		return
+	}
+	j.mu.Lock()
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+	j.mu.Unlock()
+	if j.name != "" {
+		j.r.jobsMu.Lock()
+		if j.r.jobs[j.name] == j {
+			delete(j.r.jobs, j.name)
+		}
+		j.r.jobsMu.Unlock()
+	}
+}
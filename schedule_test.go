@@ -0,0 +1,141 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunner_ScheduleAfter(t *testing.T) {
+	t.Run("Should run the action after the delay", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := make(chan time.Time, 1)
+		start := time.Now()
+		r.ScheduleAfter(10*time.Millisecond, func() { ran <- time.Now() })
+		require.GreaterOrEqual(t, (<-ran).Sub(start), 10*time.Millisecond)
+	})
+	t.Run("Should not run a cancelled action", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := false
+		cancel := r.ScheduleAfter(10*time.Millisecond, func() { ran = true })
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		require.False(t, action.ActGet(r, func() bool { return ran }))
+	})
+	t.Run("Should run the action at the given time", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := make(chan struct{})
+		at := time.Now().Add(10 * time.Millisecond)
+		r.ScheduleAt(at, func() { close(ran) })
+		<-ran
+		require.False(t, time.Now().Before(at))
+	})
+	t.Run("Should drop the action of a stopped runner", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		ran := make(chan struct{}, 1)
+		r.ScheduleAfter(time.Millisecond, func() { ran <- struct{}{} })
+		require.NoError(t, r.Close())
+		time.Sleep(10 * time.Millisecond)
+		require.Empty(t, ran)
+	})
+}
+
+func TestRunner_ScheduleEvery(t *testing.T) {
+	t.Run("Should run the action repeatedly", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		runs := make(chan struct{}, 3)
+		j, err := r.ScheduleEvery(time.Millisecond, func() {
+			select {
+			case runs <- struct{}{}:
+			default:
+			}
+		}, action.WithJitter(time.Millisecond))
+		require.NoError(t, err)
+		for range 3 {
+			<-runs
+		}
+		j.Cancel()
+	})
+	t.Run("Should find and cancel jobs by name", func(t *testing.T) {
+		r := action.New()
+		require.NoError(t, r.Start(t.Context()))
+		j, err := r.ScheduleEvery(time.Hour, func() {}, action.WithJobName("sweep"))
+		require.NoError(t, err)
+		require.Equal(t, "sweep", j.Name())
+		_, err = r.ScheduleEvery(time.Hour, func() {}, action.WithJobName("sweep"))
+		require.ErrorIs(t, err, action.ErrNameTaken)
+		got, ok := r.Job("sweep")
+		require.True(t, ok)
+		require.Same(t, j, got)
+		j.Cancel()
+		_, ok = r.Job("sweep")
+		require.False(t, ok)
+		_, err = r.ScheduleEvery(time.Hour, func() {}, action.WithJobName("sweep"))
+		require.NoError(t, err)
+	})
+	t.Run("Should cancel the job once the runner stops with an error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var runs atomic.Int64
+		r := action.New(action.WithHook(func(context.Context) error {
+			if runs.Load() >= 3 {
+				return errStop
+			}
+			return nil
+		}))
+		_, err := r.ScheduleEvery(time.Microsecond, func() { runs.Add(1) }, action.WithJobName("tick"))
+		require.NoError(t, err)
+		require.NoError(t, r.Start(t.Context()))
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errStop)
+		require.Eventually(t, func() bool {
+			_, ok := r.Job("tick")
+			return !ok
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Should reject a non-positive interval", func(t *testing.T) {
+		_, err := action.New().ScheduleEvery(0, func() {})
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
+
+func TestRunner_ScheduleCron(t *testing.T) {
+	t.Run("Should schedule at the next matching time", func(t *testing.T) {
+		r := action.New()
+		now := time.Now()
+		for spec, want := range map[string]time.Time{
+			"@yearly":      time.Date(now.Year()+1, 1, 1, 0, 0, 0, 0, time.Local),
+			"0 12 * * *":   time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.Local),
+			"*/30 * * * *": now.Truncate(30 * time.Minute).Add(30 * time.Minute),
+		} {
+			j, err := r.ScheduleCron(spec, func() {})
+			require.NoError(t, err)
+			if !want.After(now) {
+				want = want.AddDate(0, 0, 1)
+			}
+			require.Equal(t, want, j.Next(), spec)
+			j.Cancel()
+		}
+	})
+	t.Run("Should match either restricted day field", func(t *testing.T) {
+		j, err := action.New().ScheduleCron("0 0 13 * 5", func() {})
+		require.NoError(t, err)
+		defer j.Cancel()
+		next := j.Next()
+		require.True(t, next.Day() == 13 || next.Weekday() == time.Friday)
+	})
+	t.Run("Should reject invalid expressions", func(t *testing.T) {
+		for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+			_, err := action.New().ScheduleCron(spec, func() {})
+			require.ErrorIs(t, err, action.ErrInvalidOption, spec)
+		}
+	})
+}
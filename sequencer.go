@@ -0,0 +1,72 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+)
+
+// Sequencer runs actions sharing a key in submission order, while actions
+// of different keys run concurrently across a fixed pool of runners. Keys
+// are spread over the runners by hash, so two keys may share a runner.
+type Sequencer[K comparable] struct {
+	seed    maphash.Seed
+	runners []*Runner
+}
+
+// Sharded is another name for Sequencer, for code thinking of it as a
+// runner sharded by key.
+type Sharded[K comparable] = Sequencer[K]
+
+// NewSequencer returns a Sequencer over n runners created with opts.
+// n is at least 1.
+func NewSequencer[K comparable](n int, opts ...func(*Runner)) *Sequencer[K] {
+	s := &Sequencer[K]{
+		seed:    maphash.MakeSeed(),
+		runners: make([]*Runner, max(n, 1)),
+	}
+	for i := range s.runners {
+		s.runners[i] = New(opts...)
+	}
+	return s
+}
+
+// Start starts every runner of the pool.
+func (s *Sequencer[K]) Start(ctx context.Context) error {
+	var errs []error
+	for _, r := range s.runners {
+		errs = append(errs, r.Start(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Runner returns the runner executing the actions of key, to be used with
+// the Act helpers.
+func (s *Sequencer[K]) Runner(key K) Runners {
+	return s.runners[maphash.Comparable(s.seed, key)%uint64(len(s.runners))]
+}
+
+// Send enqueues the action after the ones already sent for key.
+func (s *Sequencer[K]) Send(key K, a Action) {
+	s.Runner(key).Send(a)
+}
+
+// ActKeyed is Act on the runner of key.
+func ActKeyed[K comparable](s *Sequencer[K], key K, action Action) {
+	Act(s.Runner(key), action)
+}
+
+// ActErrKeyed is ActErr on the runner of key.
+func ActErrKeyed[K comparable](s *Sequencer[K], key K, action ActionErr) error {
+	return ActErr(s.Runner(key), action)
+}
+
+// ActGetKeyed is ActGet on the runner of key.
+func ActGetKeyed[K comparable, T any](s *Sequencer[K], key K, action ActionReturn[T]) T {
+	return ActGet(s.Runner(key), action)
+}
+
+// ActGetErrKeyed is ActGetErr on the runner of key.
+func ActGetErrKeyed[K comparable, T any](s *Sequencer[K], key K, action ActionReturnWithError[T]) (T, error) {
+	return ActGetErr(s.Runner(key), action)
+}
@@ -0,0 +1,65 @@
+package action_test
+
+import (
+	"fmt"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestSequencer(t *testing.T) {
+	t.Run("Should keep the order per key", func(t *testing.T) {
+		s := action.NewSequencer[string](4, action.WithChanSize(16))
+		require.NoError(t, s.Start(t.Context()))
+		var mu sync.Mutex
+		got := map[string][]int{}
+		var wg sync.WaitGroup
+		for k := range 8 {
+			key := fmt.Sprint("key", k)
+			for i := range 50 {
+				wg.Add(1)
+				s.Send(key, func() {
+					defer wg.Done()
+					mu.Lock()
+					defer mu.Unlock()
+					got[key] = append(got[key], i)
+				})
+			}
+		}
+		wg.Wait()
+		require.Len(t, got, 8)
+		for _, seq := range got {
+			require.IsIncreasing(t, seq)
+			require.Len(t, seq, 50)
+		}
+	})
+	t.Run("Should map a key to the same runner", func(t *testing.T) {
+		s := action.NewSequencer[int](4)
+		require.Same(t, s.Runner(7), s.Runner(7))
+	})
+	t.Run("Should fail to start twice", func(t *testing.T) {
+		s := action.NewSequencer[int](2)
+		require.NoError(t, s.Start(t.Context()))
+		require.ErrorIs(t, s.Start(t.Context()), action.ErrAlreadyStarted)
+	})
+}
+
+func TestActKeyed(t *testing.T) {
+	t.Run("Should run the action on the runner of the key", func(t *testing.T) {
+		var s *action.Sharded[int] = action.NewSequencer[int](4)
+		require.NoError(t, s.Start(t.Context()))
+		counts := map[int]int{}
+		for key := range 8 {
+			action.ActKeyed(s, key, func() { counts[key]++ })
+		}
+		for key := range 8 {
+			require.Equal(t, 1, action.ActGetKeyed(s, key, func() int { return counts[key] }))
+		}
+		errKey := fmt.Errorf("key")
+		require.ErrorIs(t, action.ActErrKeyed(s, 1, func() error { return errKey }), errKey)
+		v, err := action.ActGetErrKeyed(s, 2, func() (int, error) { return 2, nil })
+		require.NoError(t, err)
+		require.Equal(t, 2, v)
+	})
+}
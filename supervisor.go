@@ -0,0 +1,269 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Strategy selects the children a Supervisor restarts when one fails.
+type Strategy int
+
+const (
+	// OneForOne restarts the failed child only.
+	OneForOne Strategy = iota
+	// OneForAll restarts every child.
+	OneForAll
+	// RestForOne restarts the failed child and the ones added after it.
+	RestForOne
+)
+
+var _ io.Closer = (*Supervisor)(nil)
+
+// Supervisor restarts its child runners when they stop with an error, such
+// as a *PanicError with WithPanicCapture. Children stopped without error,
+// or closed, are left stopped. Restarted children keep their options and
+// hooks but lose their queued actions, see Runner.Reset. Once the restart
+// budget is exhausted, the supervisor closes every child and stops with an
+// error wrapping ErrRestartLimit.
+//
+// Children can be sent to while they restart: the senders wait for the
+// restart instead of panicking, and only fail like with any stopped runner
+// once the child is left stopped.
+type Supervisor struct {
+	strategy    Strategy
+	maxRestarts int
+	within      time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+
+	children []*Runner
+	gens     []int
+	exits    chan childExit
+	restarts []time.Time
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+	err      error
+}
+
+type childExit struct {
+	i, gen int
+}
+
+// NewSupervisor returns a supervisor restarting children with strategy.
+// By default, it allows 3 restarts within 5 seconds, without backoff.
+func NewSupervisor(strategy Strategy, opts ...func(*Supervisor)) *Supervisor {
+	s := &Supervisor{
+		strategy:    strategy,
+		maxRestarts: 3,
+		within:      5 * time.Second,
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithRestartBudget allows at most n restarts within d, n being at least 0.
+func WithRestartBudget(n int, d time.Duration) func(*Supervisor) {
+	return func(s *Supervisor) {
+		s.maxRestarts = max(n, 0)
+		s.within = d
+	}
+}
+
+// WithBackoff delays restarts by min, doubled for each restart already
+// done within the budget window, up to max.
+func WithBackoff(min, max time.Duration) func(*Supervisor) {
+	return func(s *Supervisor) {
+		s.minBackoff = min
+		s.maxBackoff = max
+	}
+}
+
+// Add adds a child, started by Start in the order children were added. It
+// must be called before Start, with a runner that is not started.
+func (s *Supervisor) Add(r *Runner) {
+	r.supervise(true)
+	s.children = append(s.children, r)
+	s.gens = append(s.gens, 0)
+}
+
+// Start starts the children and supervises them until ctx is done or
+// Close is called. If a child fails to start, the ones already started are
+// closed and the error is returned.
+func (s *Supervisor) Start(ctx context.Context) error {
+	ctx, s.cancel = context.WithCancelCause(ctx)
+	s.exits = make(chan childExit, len(s.children))
+	for i, c := range s.children {
+		if err := c.Start(ctx); err != nil {
+			s.cancel(err)
+			s.unsupervise()
+			s.err = errors.Join(err, s.closeChildren(s.children[:i]))
+			close(s.done)
+			return s.err
+		}
+		s.watch(i)
+	}
+	go s.run(ctx)
+	return nil
+}
+
+// watch reports the exit of the current generation of child i.
+func (s *Supervisor) watch(i int) {
+	done, gen := s.children[i].Done(), s.gens[i]
+	go func() {
+		<-done
+		select {
+		case s.exits <- childExit{i: i, gen: gen}:
+		case <-s.done:
+		}
+	}()
+}
+
+func (s *Supervisor) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			s.stop(context.Cause(ctx))
+			return
+		case e := <-s.exits:
+			if ctx.Err() != nil {
+				s.stop(context.Cause(ctx))
+				return
+			}
+			if e.gen != s.gens[e.i] {
+				continue
+			}
+			c := s.children[e.i]
+			if err := c.Error(); err == nil || errors.Is(err, ErrClosed) {
+				c.supervise(false)
+				continue
+			}
+			if err := s.restart(ctx, e.i, c.Error()); err != nil {
+				s.stop(err)
+				return
+			}
+		}
+	}
+}
+
+// restart restarts the children affected by the failure of child i
+// according to the strategy and the budget.
+func (s *Supervisor) restart(ctx context.Context, i int, cause error) error {
+	now := time.Now()
+	s.restarts = slices.DeleteFunc(s.restarts, func(t time.Time) bool {
+		return now.Sub(t) > s.within
+	})
+	if len(s.restarts) >= s.maxRestarts {
+		return fmt.Errorf("%w: %w", ErrRestartLimit, cause)
+	}
+	s.restarts = append(s.restarts, now)
+	if d := s.backoff(); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+	targets := []int{i}
+	switch s.strategy {
+	case OneForAll:
+		targets = targets[:0]
+		for j := range s.children {
+			targets = append(targets, j)
+		}
+	case RestForOne:
+		for j := i + 1; j < len(s.children); j++ {
+			targets = append(targets, j)
+		}
+	}
+	for _, j := range slices.Backward(targets) {
+		s.gens[j]++
+		if err := s.children[j].Close(); err != nil {
+			return err
+		}
+	}
+	for _, j := range targets {
+		c := s.children[j]
+		if err := c.Reset(); err != nil {
+			return err
+		}
+		// A child left stopped before may be restarted with the others.
+		c.supervise(true)
+		if err := c.Start(ctx); err != nil {
+			return err
+		}
+		s.watch(j)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next restart.
+func (s *Supervisor) backoff() time.Duration {
+	if s.minBackoff <= 0 {
+		return 0
+	}
+	d := s.minBackoff << (len(s.restarts) - 1)
+	if s.maxBackoff > 0 && (d > s.maxBackoff || d <= 0) {
+		d = s.maxBackoff
+	}
+	return d
+}
+
+// stop closes the children and stops the supervisor with err.
+func (s *Supervisor) stop(err error) {
+	s.unsupervise()
+	s.err = errors.Join(err, s.closeChildren(s.children))
+	close(s.done)
+}
+
+// unsupervise makes the children fail their senders once stopped, as they
+// are no longer restarted.
+func (s *Supervisor) unsupervise() {
+	for _, c := range s.children {
+		c.supervise(false)
+	}
+}
+
+func (s *Supervisor) closeChildren(children []*Runner) error {
+	var errs []error
+	for _, c := range slices.Backward(children) {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Done returns a channel closed once the supervisor has stopped and closed
+// its children.
+func (s *Supervisor) Done() <-chan struct{} {
+	return s.done
+}
+
+// Error returns the error of the supervisor. To be used with Done()
+func (s *Supervisor) Error() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close stops the supervisor and its children, in reverse order, and waits
+// for them. The supervisor error is then ErrClosed unless it had already
+// stopped. It does nothing if the supervisor was never started.
+func (s *Supervisor) Close() error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel(ErrClosed)
+	<-s.done
+	return nil
+}
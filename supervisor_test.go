@@ -0,0 +1,172 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newChild returns a runner reporting its name to starts each time it
+// starts.
+func newChild(name string, starts chan<- string) *action.Runner {
+	return action.New(action.WithPanicCapture(), action.WithWarmup(func(context.Context) error {
+		starts <- name
+		return nil
+	}))
+}
+
+func crash(r *action.Runner) {
+	r.Send(func() { panic("boom") })
+}
+
+func TestSupervisor(t *testing.T) {
+	t.Run("Should restart the failed child with OneForOne", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a, b := newChild("a", starts), newChild("b", starts)
+		s := action.NewSupervisor(action.OneForOne)
+		s.Add(a)
+		s.Add(b)
+		require.NoError(t, s.Start(t.Context()))
+		defer s.Close()
+		require.ElementsMatch(t, []string{"a", "b"}, []string{<-starts, <-starts})
+		crash(a)
+		require.Equal(t, "a", <-starts)
+		require.Equal(t, 42, action.ActGet(a, func() int { return 42 }))
+		require.Empty(t, starts)
+	})
+	t.Run("Should restart every child with OneForAll", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a, b := newChild("a", starts), newChild("b", starts)
+		s := action.NewSupervisor(action.OneForAll)
+		s.Add(a)
+		s.Add(b)
+		require.NoError(t, s.Start(t.Context()))
+		defer s.Close()
+		<-starts
+		<-starts
+		crash(b)
+		require.ElementsMatch(t, []string{"a", "b"}, []string{<-starts, <-starts})
+	})
+	t.Run("Should restart named children with OneForAll", func(t *testing.T) {
+		starts := make(chan string, 8)
+		named := func(name string) *action.Runner {
+			return action.New(action.WithPanicCapture(), action.WithName(name), action.WithWarmup(func(context.Context) error {
+				starts <- name
+				return nil
+			}))
+		}
+		a, b := named("supervised-a"), named("supervised-b")
+		s := action.NewSupervisor(action.OneForAll, action.WithRestartBudget(10, time.Minute))
+		s.Add(a)
+		s.Add(b)
+		require.NoError(t, s.Start(t.Context()))
+		<-starts
+		<-starts
+		for range 5 {
+			crash(b)
+			require.ElementsMatch(t, []string{"supervised-a", "supervised-b"}, []string{<-starts, <-starts})
+		}
+		got, ok := action.Lookup("supervised-b")
+		require.True(t, ok)
+		require.Equal(t, b, got)
+		require.NoError(t, s.Error())
+		require.NoError(t, s.Close())
+		_, ok = action.Lookup("supervised-a")
+		require.False(t, ok)
+	})
+	t.Run("Should restart the next children with RestForOne", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a, b, c := newChild("a", starts), newChild("b", starts), newChild("c", starts)
+		s := action.NewSupervisor(action.RestForOne)
+		s.Add(a)
+		s.Add(b)
+		s.Add(c)
+		require.NoError(t, s.Start(t.Context()))
+		defer s.Close()
+		for range 3 {
+			<-starts
+		}
+		crash(b)
+		require.ElementsMatch(t, []string{"b", "c"}, []string{<-starts, <-starts})
+		require.Equal(t, 1, action.ActGet(a, func() int { return 1 }))
+		require.Empty(t, starts)
+	})
+	t.Run("Should give up once the budget is exhausted", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a := newChild("a", starts)
+		s := action.NewSupervisor(action.OneForOne,
+			action.WithRestartBudget(1, time.Minute),
+			action.WithBackoff(time.Millisecond, 10*time.Millisecond))
+		s.Add(a)
+		require.NoError(t, s.Start(t.Context()))
+		<-starts
+		crash(a)
+		<-starts
+		crash(a)
+		<-s.Done()
+		require.ErrorIs(t, s.Error(), action.ErrRestartLimit)
+		var pe *action.PanicError
+		require.ErrorAs(t, s.Error(), &pe)
+	})
+	t.Run("Should leave closed children stopped and close the others", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a, b := newChild("a", starts), newChild("b", starts)
+		s := action.NewSupervisor(action.OneForAll)
+		s.Add(a)
+		s.Add(b)
+		require.NoError(t, s.Start(t.Context()))
+		<-starts
+		<-starts
+		require.NoError(t, a.Close())
+		require.NoError(t, s.Close())
+		<-b.Done()
+		require.Empty(t, starts)
+		require.ErrorIs(t, s.Error(), action.ErrClosed)
+	})
+	t.Run("Should keep senders waiting while a child restarts", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a := newChild("a", starts)
+		s := action.NewSupervisor(action.OneForOne, action.WithRestartBudget(10, time.Minute))
+		s.Add(a)
+		require.NoError(t, s.Start(t.Context()))
+		defer s.Close()
+		<-starts
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					action.Tell(a, func() {})
+					action.Act(a, func() {})
+				}
+			}()
+		}
+		for range 3 {
+			crash(a)
+			require.Equal(t, "a", <-starts)
+		}
+		close(stop)
+		wg.Wait()
+		require.Equal(t, 42, action.ActGet(a, func() int { return 42 }))
+	})
+	t.Run("Should fail the senders of children left stopped", func(t *testing.T) {
+		starts := make(chan string, 8)
+		a := newChild("a", starts)
+		s := action.NewSupervisor(action.OneForOne)
+		s.Add(a)
+		require.NoError(t, s.Start(t.Context()))
+		<-starts
+		require.NoError(t, s.Close())
+		require.ErrorIs(t, a.SendErr(func() {}), action.ErrStopped)
+	})
+}
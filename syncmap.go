@@ -0,0 +1,124 @@
+package action
+
+// SyncMap is a map with the method set of sync.Map, implemented over a
+// runner. Swapping a sync.Map for it moves the map under actor ownership
+// without touching call sites, apart from the constructor.
+type SyncMap struct {
+	r Runners
+	m map[any]any
+}
+
+// NewSyncMap returns an empty SyncMap whose operations run on r.
+func NewSyncMap(r Runners) *SyncMap {
+	return &SyncMap{
+		r: r,
+		m: make(map[any]any),
+	}
+}
+
+// Load returns the value stored for the key, if any.
+func (m *SyncMap) Load(key any) (value any, ok bool) {
+	return ActGet2(m.r, func() (any, bool) {
+		v, ok := m.m[key]
+		return v, ok
+	})
+}
+
+// Store sets the value for the key.
+func (m *SyncMap) Store(key, value any) {
+	Act(m.r, func() {
+		m.m[key] = value
+	})
+}
+
+// LoadOrStore returns the existing value for the key if present. Otherwise,
+// it stores and returns the given value. loaded reports whether the value was
+// already there.
+func (m *SyncMap) LoadOrStore(key, value any) (actual any, loaded bool) {
+	return ActGet2(m.r, func() (any, bool) {
+		if v, ok := m.m[key]; ok {
+			return v, true
+		}
+		m.m[key] = value
+		return value, false
+	})
+}
+
+// LoadAndDelete deletes the value for the key, returning the previous value
+// if any.
+func (m *SyncMap) LoadAndDelete(key any) (value any, loaded bool) {
+	return ActGet2(m.r, func() (any, bool) {
+		v, ok := m.m[key]
+		delete(m.m, key)
+		return v, ok
+	})
+}
+
+// Delete deletes the value for the key.
+func (m *SyncMap) Delete(key any) {
+	Act(m.r, func() {
+		delete(m.m, key)
+	})
+}
+
+// Swap stores the value for the key and returns the previous value if any.
+func (m *SyncMap) Swap(key, value any) (previous any, loaded bool) {
+	return ActGet2(m.r, func() (any, bool) {
+		v, ok := m.m[key]
+		m.m[key] = value
+		return v, ok
+	})
+}
+
+// CompareAndSwap swaps the old and new values for the key if the value
+// stored is equal to old. The old value must be of a comparable type.
+func (m *SyncMap) CompareAndSwap(key, old, new any) (swapped bool) {
+	return ActGet(m.r, func() bool {
+		v, ok := m.m[key]
+		if !ok || v != old {
+			return false
+		}
+		m.m[key] = new
+		return true
+	})
+}
+
+// CompareAndDelete deletes the entry for the key if its value is equal to
+// old. The old value must be of a comparable type.
+func (m *SyncMap) CompareAndDelete(key, old any) (deleted bool) {
+	return ActGet(m.r, func() bool {
+		v, ok := m.m[key]
+		if !ok || v != old {
+			return false
+		}
+		delete(m.m, key)
+		return true
+	})
+}
+
+// Range calls f for each key and value, stopping if f returns false.
+// It iterates over a snapshot taken in a single action, so f runs outside of
+// the runner and may call the other methods of the map.
+func (m *SyncMap) Range(f func(key, value any) bool) {
+	keys, values := ActGet2(m.r, func() ([]any, []any) {
+		keys := make([]any, 0, len(m.m))
+		values := make([]any, 0, len(m.m))
+		for k, v := range m.m {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		return keys, values
+	})
+	for i := range keys {
+		if !f(keys[i], values[i]) {
+			return
+		}
+	}
+}
+
+// Clear deletes all the entries.
+func (m *SyncMap) Clear() {
+	Act(m.r, func() {
+		clear(m.m)
+	})
+}
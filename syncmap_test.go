@@ -0,0 +1,81 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newSyncMap(t *testing.T) *action.SyncMap {
+	r := action.New()
+	require.NoError(t, r.Start(t.Context()))
+	return action.NewSyncMap(r)
+}
+
+func TestSyncMap(t *testing.T) {
+	t.Run("Should store, load and delete values", func(t *testing.T) {
+		m := newSyncMap(t)
+		_, ok := m.Load("a")
+		require.False(t, ok)
+		m.Store("a", 1)
+		v, ok := m.Load("a")
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+		m.Delete("a")
+		_, ok = m.Load("a")
+		require.False(t, ok)
+	})
+	t.Run("Should load or store", func(t *testing.T) {
+		m := newSyncMap(t)
+		v, loaded := m.LoadOrStore("a", 1)
+		require.False(t, loaded)
+		require.Equal(t, 1, v)
+		v, loaded = m.LoadOrStore("a", 2)
+		require.True(t, loaded)
+		require.Equal(t, 1, v)
+	})
+	t.Run("Should load and delete", func(t *testing.T) {
+		m := newSyncMap(t)
+		m.Store("a", 1)
+		v, loaded := m.LoadAndDelete("a")
+		require.True(t, loaded)
+		require.Equal(t, 1, v)
+		_, loaded = m.LoadAndDelete("a")
+		require.False(t, loaded)
+	})
+	t.Run("Should swap and compare", func(t *testing.T) {
+		m := newSyncMap(t)
+		_, loaded := m.Swap("a", 1)
+		require.False(t, loaded)
+		prev, loaded := m.Swap("a", 2)
+		require.True(t, loaded)
+		require.Equal(t, 1, prev)
+		require.False(t, m.CompareAndSwap("a", 1, 3))
+		require.True(t, m.CompareAndSwap("a", 2, 3))
+		require.False(t, m.CompareAndDelete("a", 2))
+		require.True(t, m.CompareAndDelete("a", 3))
+	})
+	t.Run("Should range and allow calls from the callback", func(t *testing.T) {
+		m := newSyncMap(t)
+		m.Store("a", 1)
+		m.Store("b", 2)
+		sum := 0
+		m.Range(func(key, value any) bool {
+			sum += value.(int)
+			m.Delete(key)
+			return true
+		})
+		require.Equal(t, 3, sum)
+		m.Range(func(key, value any) bool {
+			t.Fatal("map should be empty")
+			return false
+		})
+	})
+	t.Run("Should clear", func(t *testing.T) {
+		m := newSyncMap(t)
+		m.Store("a", 1)
+		m.Clear()
+		_, ok := m.Load("a")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,124 @@
+package action
+
+import (
+	"context"
+	"errors"
+)
+
+// TaskQueue dispatches tasks to a fixed number of worker runners. Each
+// worker processes one task at a time and acknowledges it: a failed task is
+// queued again until its attempts are exhausted, then handed to the dead
+// letter function. The queue itself is owned by its own runner.
+type TaskQueue[T any] struct {
+	r           *Runner
+	workers     []*Runner
+	handle      func(context.Context, T) error
+	maxAttempts int
+	deadLetter  func(T, error)
+
+	// Owned by r.
+	queue    []queuedTask[T]
+	idle     []*Runner
+	inFlight int
+}
+
+type queuedTask[T any] struct {
+	task    T
+	attempt int
+}
+
+// NewTaskQueue returns a TaskQueue processing tasks with handle on the given
+// number of workers, at least 1. By default a task is attempted once and its
+// failure is dropped.
+func NewTaskQueue[T any](workers int, handle func(ctx context.Context, task T) error, opts ...func(*TaskQueue[T])) *TaskQueue[T] {
+	q := &TaskQueue[T]{
+		r:           New(WithChanSize(64)),
+		workers:     make([]*Runner, max(workers, 1)),
+		handle:      handle,
+		maxAttempts: 1,
+	}
+	for i := range q.workers {
+		q.workers[i] = New()
+	}
+	q.idle = append(q.idle, q.workers...)
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// WithTaskAttempts sets how many times a failing task is attempted before
+// being dead lettered. If 0, will be ignored.
+func WithTaskAttempts[T any](n int) func(*TaskQueue[T]) {
+	return func(q *TaskQueue[T]) {
+		q.maxAttempts = max(n, 1)
+	}
+}
+
+// WithTaskDeadLetter sets the function receiving the tasks that failed all
+// their attempts, with the last error. It runs on the queue runner and must
+// not enqueue synchronously.
+func WithTaskDeadLetter[T any](f func(task T, err error)) func(*TaskQueue[T]) {
+	return func(q *TaskQueue[T]) {
+		q.deadLetter = f
+	}
+}
+
+// Start starts the queue and its workers.
+func (q *TaskQueue[T]) Start(ctx context.Context) error {
+	errs := []error{q.r.Start(ctx)}
+	for _, w := range q.workers {
+		errs = append(errs, w.Start(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Enqueue adds the task to the queue without waiting for it to be processed.
+func (q *TaskQueue[T]) Enqueue(task T) {
+	Tell(q.r, func() {
+		q.queue = append(q.queue, queuedTask[T]{task: task, attempt: 1})
+		q.dispatch()
+	})
+}
+
+// Len returns the number of tasks queued or being processed.
+func (q *TaskQueue[T]) Len() int {
+	return ActGet(q.r, func() int {
+		return len(q.queue) + q.inFlight
+	})
+}
+
+// dispatch hands queued tasks to idle workers. It runs on the queue runner.
+func (q *TaskQueue[T]) dispatch() {
+	for len(q.queue) > 0 && len(q.idle) > 0 {
+		t := q.queue[0]
+		clear(q.queue[:1])
+		q.queue = q.queue[1:]
+		w := q.idle[len(q.idle)-1]
+		q.idle = q.idle[:len(q.idle)-1]
+		q.inFlight++
+		// The worker is idle, so its queue has room and this does not block.
+		w.Send(func() {
+			err := q.handle(w.Ctx(), t.task)
+			// The control lane does not panic once the queue is stopped.
+			q.r.command(func() {
+				q.ack(w, t, err)
+			})
+		})
+	}
+}
+
+// ack records the outcome of a task. It runs on the queue runner.
+func (q *TaskQueue[T]) ack(w *Runner, t queuedTask[T], err error) {
+	q.inFlight--
+	q.idle = append(q.idle, w)
+	switch {
+	case err == nil:
+	case t.attempt < q.maxAttempts:
+		t.attempt++
+		q.queue = append(q.queue, t)
+	case q.deadLetter != nil:
+		q.deadLetter(t.task, err)
+	}
+	q.dispatch()
+}
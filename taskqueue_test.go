@@ -0,0 +1,52 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskQueue(t *testing.T) {
+	t.Run("Should process every task across the workers", func(t *testing.T) {
+		var mu sync.Mutex
+		var done []int
+		q := action.NewTaskQueue(4, func(_ context.Context, n int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			done = append(done, n)
+			return nil
+		})
+		require.NoError(t, q.Start(t.Context()))
+		var want []int
+		for i := range 50 {
+			q.Enqueue(i)
+			want = append(want, i)
+		}
+		require.Eventually(t, func() bool {
+			return q.Len() == 0
+		}, time.Second, time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		require.ElementsMatch(t, want, done)
+	})
+	t.Run("Should retry then dead letter failing tasks", func(t *testing.T) {
+		errTask := errors.New("task")
+		var attempts atomic.Int64
+		dead := make(chan error, 1)
+		q := action.NewTaskQueue(2, func(_ context.Context, n int) error {
+			attempts.Add(1)
+			return errTask
+		}, action.WithTaskAttempts[int](3), action.WithTaskDeadLetter(func(n int, err error) {
+			dead <- err
+		}))
+		require.NoError(t, q.Start(t.Context()))
+		q.Enqueue(1)
+		require.ErrorIs(t, <-dead, errTask)
+		require.Equal(t, int64(3), attempts.Load())
+	})
+}
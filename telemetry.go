@@ -0,0 +1,136 @@
+package action
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Names of the measurements reported to a Telemetry provider.
+const (
+	// MetricSent counts the actions sent to the runner.
+	MetricSent = "action.sent"
+	// MetricExecuted counts the actions executed by the runner.
+	MetricExecuted = "action.executed"
+	// MetricDuration observes how long each action took, in seconds, hooks
+	// included.
+	MetricDuration = "action.duration_seconds"
+	// MetricTimeout counts the actions of ActWithContext and ActGetErrCtx
+	// skipped because their caller's deadline passed while they were queued.
+	MetricTimeout = "action.timeouts"
+	// SpanExecute is the span covering an action and its hooks.
+	SpanExecute = "action.execute"
+)
+
+// Telemetry receives the measurements of a runner. It keeps the package free
+// of any metrics or tracing dependency: adapters for Prometheus,
+// OpenTelemetry or statsd implement it elsewhere. Methods are called from
+// the runner goroutine and, for MetricSent, from the senders, so they must
+// be safe for concurrent use.
+type Telemetry interface {
+	// Count adds delta to the named counter.
+	Count(name string, delta int64)
+	// Observe records value in the named histogram.
+	Observe(name string, value float64)
+	// StartSpan starts the named span and returns the context carrying it
+	// and the function ending it with the resulting error, if any.
+	StartSpan(ctx context.Context, name string) (context.Context, func(error))
+}
+
+// CallerTracer is implemented by Telemetry providers continuing the caller's
+// trace into the actions of ActWithContext and ActGetErrCtx.
+type CallerTracer interface {
+	// StartCallerSpan starts the span of an action sent at enqueued with
+	// the caller context, ctx being the context the action runs with. It
+	// returns the context given to the action and the function ending the
+	// span with the action error, if any.
+	StartCallerSpan(caller, ctx context.Context, enqueued time.Time) (context.Context, func(error))
+}
+
+// WithTelemetry reports the runner measurements to t. The span context is
+// the one given to hooks. The inline fast path is skipped when telemetry is
+// configured so every action is measured. Given several times, every
+// provider receives the measurements, spans being nested in order.
+func WithTelemetry(t Telemetry) func(*Runner) {
+	return func(r *Runner) {
+		if t == nil {
+			r.invalid("nil telemetry")
+			return
+		}
+		if r.telemetry != nil {
+			t = teeTelemetry{r.telemetry, t}
+		}
+		r.telemetry = t
+	}
+}
+
+// teeTelemetry reports to several providers.
+type teeTelemetry []Telemetry
+
+func (ts teeTelemetry) Count(name string, delta int64) {
+	for _, t := range ts {
+		t.Count(name, delta)
+	}
+}
+
+func (ts teeTelemetry) Observe(name string, value float64) {
+	for _, t := range ts {
+		t.Observe(name, value)
+	}
+}
+
+func (ts teeTelemetry) StartSpan(ctx context.Context, name string) (context.Context, func(error)) {
+	ends := make([]func(error), len(ts))
+	for i, t := range ts {
+		ctx, ends[i] = t.StartSpan(ctx, name)
+	}
+	return ctx, func(err error) {
+		for _, end := range slices.Backward(ends) {
+			end(err)
+		}
+	}
+}
+
+func (ts teeTelemetry) StartCallerSpan(caller, ctx context.Context, enqueued time.Time) (context.Context, func(error)) {
+	var ends []func(error)
+	for _, t := range ts {
+		if ct, ok := t.(CallerTracer); ok {
+			var end func(error)
+			ctx, end = ct.StartCallerSpan(caller, ctx, enqueued)
+			ends = append(ends, end)
+		}
+	}
+	return ctx, func(err error) {
+		for _, end := range slices.Backward(ends) {
+			end(err)
+		}
+	}
+}
+
+// callerSpan starts the span of an action of a caller if the telemetry
+// provider of r is a CallerTracer.
+func callerSpan(caller, ctx context.Context, r Runners, enqueued time.Time) (context.Context, func(error)) {
+	if tr, ok := r.(interface{ callerTracer() CallerTracer }); ok {
+		if ct := tr.callerTracer(); ct != nil {
+			return ct.StartCallerSpan(caller, ctx, enqueued)
+		}
+	}
+	return ctx, func(error) {}
+}
+
+func (r *Runner) callerTracer() CallerTracer {
+	ct, _ := r.telemetry.(CallerTracer)
+	return ct
+}
+
+// executeMeasured is execute reporting to the telemetry provider.
+func (r *Runner) executeMeasured(ctx context.Context, action Action) error {
+	t := r.telemetry
+	ctx, end := t.StartSpan(ctx, SpanExecute)
+	start := time.Now()
+	err := r.execute(ctx, action)
+	t.Observe(MetricDuration, time.Since(start).Seconds())
+	t.Count(MetricExecuted, 1)
+	end(err)
+	return err
+}
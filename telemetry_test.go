@@ -0,0 +1,124 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type spanKey struct{}
+
+type recordingTelemetry struct {
+	mu       sync.Mutex
+	counts   map[string]int64
+	observed map[string]int
+	spans    []error
+}
+
+func newRecordingTelemetry() *recordingTelemetry {
+	return &recordingTelemetry{counts: map[string]int64{}, observed: map[string]int{}}
+}
+
+func (t *recordingTelemetry) Count(name string, delta int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[name] += delta
+}
+
+func (t *recordingTelemetry) Observe(name string, _ float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observed[name]++
+}
+
+func (t *recordingTelemetry) StartSpan(ctx context.Context, name string) (context.Context, func(error)) {
+	return context.WithValue(ctx, spanKey{}, name), func(err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.spans = append(t.spans, err)
+	}
+}
+
+func (t *recordingTelemetry) snapshot() (map[string]int64, map[string]int, []error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts, t.observed, t.spans
+}
+
+func TestWithTelemetry(t *testing.T) {
+	t.Run("Should report sent and executed actions", func(t *testing.T) {
+		tel := newRecordingTelemetry()
+		r := action.New(action.WithTelemetry(tel), action.WithInlineExecution())
+		require.NoError(t, r.Start(t.Context()))
+		for range 3 {
+			action.Act(r, func() {})
+		}
+		require.Eventually(t, func() bool {
+			counts, _, _ := tel.snapshot()
+			return counts[action.MetricExecuted] == 3
+		}, time.Second, time.Millisecond)
+		counts, observed, spans := tel.snapshot()
+		require.Equal(t, int64(3), counts[action.MetricSent])
+		require.Equal(t, 3, observed[action.MetricDuration])
+		require.Equal(t, []error{nil, nil, nil}, spans)
+	})
+	t.Run("Should give the span context to hooks and end it with their error", func(t *testing.T) {
+		tel := newRecordingTelemetry()
+		errHook := errors.New("hook")
+		r := action.New(action.WithTelemetry(tel), action.WithHook(func(ctx context.Context) error {
+			if ctx.Value(spanKey{}) != action.SpanExecute {
+				return nil
+			}
+			return errHook
+		}))
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(func() {})
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), errHook)
+		_, _, spans := tel.snapshot()
+		require.Equal(t, []error{errHook}, spans)
+	})
+	t.Run("Should count the actions skipped after their caller's deadline", func(t *testing.T) {
+		tel := newRecordingTelemetry()
+		r := action.New(action.WithTelemetry(tel), action.WithChanSize(2))
+		require.NoError(t, r.Start(t.Context()))
+		started, release := make(chan struct{}), make(chan struct{})
+		r.Send(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		require.Equal(t, 0, r.Len())
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := action.ActGetCtx(ctx, r, func() int { return 1 })
+		require.ErrorIs(t, err, action.ErrTimeout)
+		require.Equal(t, 1, r.Len())
+		close(release)
+		require.Eventually(t, func() bool {
+			counts, _, _ := tel.snapshot()
+			return counts[action.MetricTimeout] == 1
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Should report to every provider", func(t *testing.T) {
+		a, b := newRecordingTelemetry(), newRecordingTelemetry()
+		r := action.New(action.WithTelemetry(a), action.WithTelemetry(b))
+		require.NoError(t, r.Start(t.Context()))
+		action.Act(r, func() {})
+		require.NoError(t, r.Close())
+		for _, tel := range []*recordingTelemetry{a, b} {
+			counts, observed, spans := tel.snapshot()
+			require.Equal(t, int64(1), counts[action.MetricExecuted])
+			require.Equal(t, 1, observed[action.MetricDuration])
+			require.Len(t, spans, 1)
+		}
+	})
+	t.Run("Should reject a nil provider", func(t *testing.T) {
+		_, err := action.NewChecked(action.WithTelemetry(nil))
+		require.ErrorIs(t, err, action.ErrInvalidOption)
+	})
+}
@@ -0,0 +1,25 @@
+package action
+
+// Pair holds two values, such as the results of ActGet2, so they can travel
+// through a single channel or slice.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Unpack returns the values of the pair.
+func (p Pair[A, B]) Unpack() (A, B) {
+	return p.First, p.Second
+}
+
+// Triple holds three values, such as the results of ActGet3.
+type Triple[A, B, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Unpack returns the values of the triple.
+func (t Triple[A, B, C]) Unpack() (A, B, C) {
+	return t.First, t.Second, t.Third
+}
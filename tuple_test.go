@@ -0,0 +1,26 @@
+package action_test
+
+import (
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPair(t *testing.T) {
+	t.Run("Should travel through a channel", func(t *testing.T) {
+		c := make(chan action.Pair[string, int], 1)
+		c <- action.Pair[string, int]{First: "a", Second: 1}
+		k, v := (<-c).Unpack()
+		require.Equal(t, "a", k)
+		require.Equal(t, 1, v)
+	})
+}
+
+func TestTriple(t *testing.T) {
+	t.Run("Should unpack in order", func(t *testing.T) {
+		a, b, c := action.Triple[int, string, bool]{First: 1, Second: "b", Third: true}.Unpack()
+		require.Equal(t, 1, a)
+		require.Equal(t, "b", b)
+		require.True(t, c)
+	})
+}
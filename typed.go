@@ -0,0 +1,82 @@
+package action
+
+import "context"
+
+// Receive handles a message sent to a TypedRunner.
+type Receive[T any] func(ctx context.Context, msg T)
+
+// TypedRunner is an actor receiving messages of type T instead of closures.
+// Messages are handled one at a time, in order, by the receive function on
+// the runner goroutine.
+type TypedRunner[T any] struct {
+	runner    *Runner
+	behaviors []Receive[T]
+}
+
+// NewTyped returns a TypedRunner handling messages with receive. The options
+// are the ones of New.
+func NewTyped[T any](receive Receive[T], opts ...func(*Runner)) *TypedRunner[T] {
+	return &TypedRunner[T]{
+		runner:    New(opts...),
+		behaviors: []Receive[T]{receive},
+	}
+}
+
+// Start starts the runner on a separated goroutine
+func (t *TypedRunner[T]) Start(ctx context.Context) error {
+	return t.runner.Start(ctx)
+}
+
+// Send enqueues the message.
+func (t *TypedRunner[T]) Send(msg T) {
+	t.runner.Send(func() {
+		t.behaviors[len(t.behaviors)-1](t.runner.Ctx(), msg)
+	})
+}
+
+// Become makes receive handle the next messages, until Unbecome is called.
+// Behaviors stack up, so protocol phases can be entered and left in order.
+// It must only be called from within a receive function.
+func (t *TypedRunner[T]) Become(receive Receive[T]) {
+	t.behaviors = append(t.behaviors, receive)
+}
+
+// Unbecome restores the behavior active before the last Become. It reports
+// false, keeping the initial behavior, if there is nothing to restore.
+// It must only be called from within a receive function.
+func (t *TypedRunner[T]) Unbecome() bool {
+	n := len(t.behaviors)
+	if n == 1 {
+		return false
+	}
+	t.behaviors[n-1] = nil
+	t.behaviors = t.behaviors[:n-1]
+	return true
+}
+
+// Ctx returns the context
+func (t *TypedRunner[T]) Ctx() context.Context {
+	return t.runner.Ctx()
+}
+
+// Done returns a channel closed when the runner is stopped.
+func (t *TypedRunner[T]) Done() <-chan struct{} {
+	return t.runner.Done()
+}
+
+// Subscribe returns a channel receiving the runner error once it is
+// stopped, see Runner.Subscribe.
+func (t *TypedRunner[T]) Subscribe() <-chan error {
+	return t.runner.Subscribe()
+}
+
+// Error returns the error of the runner. To be used with Done()
+func (t *TypedRunner[T]) Error() error {
+	return t.runner.Error()
+}
+
+// Runner returns the underlying runner, to use the Act helpers alongside
+// messages.
+func (t *TypedRunner[T]) Runner() *Runner {
+	return t.runner
+}
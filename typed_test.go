@@ -0,0 +1,94 @@
+package action_test
+
+import (
+	"context"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type greet struct {
+	name string
+}
+
+func TestNewTyped(t *testing.T) {
+	t.Run("Should handle messages in order", func(t *testing.T) {
+		var got []string
+		r := action.NewTyped(func(ctx context.Context, msg greet) {
+			got = append(got, msg.name)
+		}, action.WithChanSize(4))
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(greet{name: "alice"})
+		r.Send(greet{name: "bob"})
+		require.Equal(t, []string{"alice", "bob"}, action.ActGet(r.Runner(), func() []string {
+			return got
+		}))
+	})
+	t.Run("Should pass the runner context", func(t *testing.T) {
+		ctxs := make(chan context.Context, 1)
+		r := action.NewTyped(func(ctx context.Context, msg int) {
+			ctxs <- ctx
+		})
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(1)
+		require.Equal(t, r.Ctx(), <-ctxs)
+	})
+	t.Run("Should stop with its context", func(t *testing.T) {
+		r := action.NewTyped(func(ctx context.Context, msg int) {})
+		ctx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, r.Start(ctx))
+		cancel()
+		<-r.Done()
+		require.ErrorIs(t, r.Error(), context.Canceled)
+	})
+}
+
+func TestTypedRunner_Become(t *testing.T) {
+	t.Run("Should switch and restore behaviors", func(t *testing.T) {
+		var got []string
+		var r *action.TypedRunner[string]
+		var steady action.Receive[string]
+		steady = func(ctx context.Context, msg string) {
+			if msg == "close" {
+				got = append(got, "draining")
+				r.Unbecome()
+				return
+			}
+			got = append(got, "steady:"+msg)
+		}
+		r = action.NewTyped(func(ctx context.Context, msg string) {
+			if msg == "hello" {
+				got = append(got, "handshake")
+				r.Become(steady)
+				return
+			}
+			got = append(got, "ignored:"+msg)
+		}, action.WithChanSize(8))
+		require.NoError(t, r.Start(t.Context()))
+		for _, msg := range []string{"data", "hello", "data", "close", "data"} {
+			r.Send(msg)
+		}
+		require.Equal(t, []string{
+			"ignored:data",
+			"handshake",
+			"steady:data",
+			"draining",
+			"ignored:data",
+		}, action.ActGet(r.Runner(), func() []string {
+			return got
+		}))
+	})
+	t.Run("Should keep the initial behavior", func(t *testing.T) {
+		unbecame := make(chan bool, 2)
+		var r *action.TypedRunner[int]
+		r = action.NewTyped(func(ctx context.Context, msg int) {
+			r.Become(func(ctx context.Context, msg int) {})
+			unbecame <- r.Unbecome()
+			unbecame <- r.Unbecome()
+		})
+		require.NoError(t, r.Start(t.Context()))
+		r.Send(1)
+		require.True(t, <-unbecame)
+		require.False(t, <-unbecame)
+	})
+}
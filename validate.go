@@ -0,0 +1,145 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidValue is wrapped by the errors of Validate.
+var ErrInvalidValue = errors.New("invalid value")
+
+// Validator is implemented by values checking their own consistency.
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks v against the validate tags of its struct fields, nested
+// structs included, then with its Validate method if it or a pointer to it
+// implements Validator. Tags hold comma separated rules:
+//
+//   - required: the field is not its zero value
+//   - min=n, max=n: bounds of a number, or of the length of a string,
+//     slice or map
+//   - regexp=expr: a string matches expr; it must be the last rule as expr
+//     may contain commas
+//
+// For example:
+//
+//	type Config struct {
+//		Name  string `validate:"required,regexp=^[a-z]+$"`
+//		Port  int    `validate:"min=1,max=65535"`
+//	}
+func Validate(v any) error {
+	if err := validateStruct(reflect.ValueOf(v), ""); err != nil {
+		return err
+	}
+	val, ok := v.(Validator)
+	if rv := reflect.ValueOf(v); !ok && rv.IsValid() && rv.Kind() != reflect.Pointer {
+		// A value lacks the methods of its pointer, check a copy through one.
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		val, ok = p.Interface().(Validator)
+	}
+	if ok {
+		if err := val.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+		}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, prefix string) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := prefix + f.Name
+		fv := v.Field(i)
+		if tag, ok := f.Tag.Lookup("validate"); ok {
+			if err := validateField(fv, tag); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidValue, name, err)
+			}
+		}
+		if err := validateStruct(fv, name+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateField(v reflect.Value, tag string) error {
+	for tag != "" {
+		rule, rest, _ := strings.Cut(tag, ",")
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if v.IsZero() {
+				return errors.New("required")
+			}
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return fmt.Errorf("bad %s rule %q", name, arg)
+			}
+			n, ok := measure(v)
+			if !ok {
+				return fmt.Errorf("%s rule on a %s", name, v.Kind())
+			}
+			if name == "min" && n < limit {
+				return fmt.Errorf("%v is below the minimum %v", n, limit)
+			}
+			if name == "max" && n > limit {
+				return fmt.Errorf("%v is above the maximum %v", n, limit)
+			}
+		case "regexp":
+			// The expression takes the rest of the tag.
+			expr := strings.TrimPrefix(tag, "regexp=")
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("bad regexp rule: %w", err)
+			}
+			if v.Kind() != reflect.String {
+				return fmt.Errorf("regexp rule on a %s", v.Kind())
+			}
+			if !re.MatchString(v.String()) {
+				return fmt.Errorf("%q does not match %s", v.String(), expr)
+			}
+			return nil
+		case "":
+		default:
+			return fmt.Errorf("unknown rule %q", name)
+		}
+		tag = rest
+	}
+	return nil
+}
+
+// measure returns the number a min or max rule applies to.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
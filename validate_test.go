@@ -0,0 +1,77 @@
+package action_test
+
+import (
+	"errors"
+	"github.com/neonima/action"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type listener struct {
+	Host string `validate:"required,regexp=^[a-z.]+$"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+type server struct {
+	Name     string   `validate:"required"`
+	Tags     []string `validate:"max=2"`
+	Listener listener
+	Replicas int
+}
+
+func (s server) Validate() error {
+	if s.Replicas < 0 {
+		return errors.New("negative replicas")
+	}
+	return nil
+}
+
+type pool struct {
+	Size int
+}
+
+func (p *pool) Validate() error {
+	if p.Size < 0 {
+		return errors.New("negative size")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	valid := server{Name: "api", Listener: listener{Host: "example.com", Port: 80}}
+	tt := []struct {
+		title  string
+		mutate func(*server)
+		field  string
+	}{
+		{title: "Should accept a valid value", mutate: func(*server) {}},
+		{title: "Should require fields", mutate: func(s *server) { s.Name = "" }, field: "Name"},
+		{title: "Should bound lengths", mutate: func(s *server) { s.Tags = []string{"a", "b", "c"} }, field: "Tags"},
+		{title: "Should bound nested numbers", mutate: func(s *server) { s.Listener.Port = 0 }, field: "Listener.Port"},
+		{title: "Should match expressions", mutate: func(s *server) { s.Listener.Host = "EXAMPLE" }, field: "Listener.Host"},
+		{title: "Should call Validate", mutate: func(s *server) { s.Replicas = -1 }, field: "negative replicas"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.title, func(t *testing.T) {
+			s := valid
+			tc.mutate(&s)
+			err := action.Validate(s)
+			if tc.field == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, action.ErrInvalidValue)
+			require.ErrorContains(t, err, tc.field)
+		})
+	}
+}
+
+func TestValidate_PointerReceiver(t *testing.T) {
+	t.Run("Should call Validate of the pointer on a value", func(t *testing.T) {
+		require.ErrorIs(t, action.Validate(pool{Size: -1}), action.ErrInvalidValue)
+		require.NoError(t, action.Validate(pool{Size: 1}))
+	})
+	t.Run("Should call Validate on a pointer", func(t *testing.T) {
+		require.ErrorIs(t, action.Validate(&pool{Size: -1}), action.ErrInvalidValue)
+	})
+}